@@ -31,13 +31,46 @@ const docTemplate = `{
       }
     ],
     "paths": {
-      "/chats": {
+      "/admin/ai-requests": {
         "get": {
           "tags": [
-            "chats"
+            "admin"
+          ],
+          "summary": "List AI requests",
+          "description": "Lists the requests made to Meeyok AI with their status and attempts, newest first",
+          "parameters": [
+            {
+              "name": "status",
+              "in": "query",
+              "description": "Only requests in this status",
+              "schema": {
+                "type": "string",
+                "enum": [
+                  "pending",
+                  "answered",
+                  "failed",
+                  "timed_out"
+                ]
+              }
+            },
+            {
+              "name": "before",
+              "in": "query",
+              "description": "Only requests older than this correlation ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of requests",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
+            }
           ],
-          "summary": "List all chats",
-          "description": "Retrieves a list of all available chats",
           "responses": {
             "200": {
               "description": "OK",
@@ -46,14 +79,14 @@ const docTemplate = `{
                   "schema": {
                     "type": "array",
                     "items": {
-                      "$ref": "#/components/schemas/models.Chat"
+                      "$ref": "#/components/schemas/models.AIRequest"
                     }
                   }
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "400": {
+              "description": "Bad Request",
               "content": {
                 "application/json": {
                   "schema": {
@@ -61,44 +94,9 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
-          "security": [
-            {
-              "Bearer": []
-            }
-          ]
-        },
-        "post": {
-          "tags": [
-            "chats"
-          ],
-          "summary": "Create a new chat",
-          "description": "Creates a new chat with the provided chat details",
-          "requestBody": {
-            "description": "Chat details",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "$ref": "#/components/schemas/dtos.CreateChatRequest"
-                }
-              }
-            },
-            "required": true
-          },
-          "responses": {
-            "200": {
-              "description": "OK",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "$ref": "#/components/schemas/models.Chat"
-                  }
-                }
-              }
             },
-            "400": {
-              "description": "Bad Request",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
@@ -122,26 +120,41 @@ const docTemplate = `{
             {
               "Bearer": []
             }
-          ],
-          "x-codegen-request-body-name": "chat"
+          ]
         }
       },
-      "/chats/user/{type}": {
+      "/admin/dead-letters": {
         "get": {
           "tags": [
-            "chats"
+            "admin"
           ],
-          "summary": "Get user chats based on type",
-          "description": "Retrieves chats based on the given type (group, friend, non-friend)",
+          "summary": "List dead letters",
+          "description": "Lists the queue messages that could not be handled, newest first",
           "parameters": [
             {
-              "name": "type",
-              "in": "path",
-              "description": "Type of chat (group, friend, non-friend)",
-              "required": true,
+              "name": "before",
+              "in": "query",
+              "description": "Only dead letters older than this dead letter ID",
               "schema": {
                 "type": "string"
               }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of dead letters",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
+            },
+            {
+              "name": "pending",
+              "in": "query",
+              "description": "Leave out the dead letters that were replayed",
+              "schema": {
+                "type": "boolean"
+              }
             }
           ],
           "responses": {
@@ -150,10 +163,7 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "array",
-                    "items": {
-                      "$ref": "#/components/schemas/models.Chat"
-                    }
+                    "$ref": "#/components/schemas/models.DeadLetterPage"
                   }
                 }
               }
@@ -168,6 +178,16 @@ const docTemplate = `{
                 }
               }
             },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
             "500": {
               "description": "Internal Server Error",
               "content": {
@@ -186,40 +206,22 @@ const docTemplate = `{
           ]
         }
       },
-      "/chats/{id}": {
-        "get": {
+      "/admin/dead-letters/{id}/replay": {
+        "post": {
           "tags": [
-            "chats"
+            "admin"
           ],
-          "summary": "Get a specific chat by ID",
-          "description": "Retrieves a chat with messages based on the provided ID",
+          "summary": "Replay a dead letter",
+          "description": "Sends a dead letter back into the queue it came from to be handled again, a dead letter is only replayed once",
           "parameters": [
             {
               "name": "id",
               "in": "path",
-              "description": "Chat ID",
+              "description": "Dead letter ID",
               "required": true,
               "schema": {
                 "type": "string"
               }
-            },
-            {
-              "name": "page",
-              "in": "query",
-              "description": "Page number for pagination",
-              "schema": {
-                "type": "integer",
-                "default": 1
-              }
-            },
-            {
-              "name": "num-message",
-              "in": "query",
-              "description": "Number of messages per page",
-              "schema": {
-                "type": "integer",
-                "default": 10
-              }
             }
           ],
           "responses": {
@@ -228,13 +230,33 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Chat"
+                    "$ref": "#/components/schemas/models.DeadLetter"
                   }
                 }
               }
             },
-            "400": {
-              "description": "Bad Request",
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "409": {
+              "description": "Conflict",
               "content": {
                 "application/json": {
                   "schema": {
@@ -259,51 +281,31 @@ const docTemplate = `{
               "Bearer": []
             }
           ]
-        },
-        "put": {
+        }
+      },
+      "/chats": {
+        "get": {
           "tags": [
             "chats"
           ],
-          "summary": "Update a chat",
-          "description": "Updates the details of an existing chat",
-          "parameters": [
-            {
-              "name": "id",
-              "in": "path",
-              "description": "Chat ID",
-              "required": true,
-              "schema": {
-                "type": "string"
-              }
-            }
-          ],
-          "requestBody": {
-            "description": "Updated chat details",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "$ref": "#/components/schemas/models.Chat"
-                }
-              }
-            },
-            "required": true
-          },
+          "summary": "List all chats",
+          "description": "Retrieves a list of all available chats, only for admins",
           "responses": {
             "200": {
               "description": "OK",
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.Chat"
                     }
                   }
                 }
               }
             },
-            "400": {
-              "description": "Bad Request",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
@@ -327,36 +329,42 @@ const docTemplate = `{
             {
               "Bearer": []
             }
-          ],
-          "x-codegen-request-body-name": "chat"
+          ]
         },
-        "delete": {
+        "post": {
           "tags": [
             "chats"
           ],
-          "summary": "Delete a chat",
-          "description": "Deletes a chat by its ID",
-          "parameters": [
-            {
-              "name": "id",
-              "in": "path",
-              "description": "Chat ID",
-              "required": true,
-              "schema": {
-                "type": "string"
+          "summary": "Create a new chat",
+          "description": "Creates a new chat with the provided chat details",
+          "requestBody": {
+            "description": "Chat details",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.CreateChatRequest"
+                }
               }
-            }
-          ],
+            },
+            "required": true
+          },
           "responses": {
             "200": {
               "description": "OK",
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
+                    "$ref": "#/components/schemas/models.Chat"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
@@ -376,33 +384,23 @@ const docTemplate = `{
             {
               "Bearer": []
             }
-          ]
+          ],
+          "x-codegen-request-body-name": "chat"
         }
       },
-      "/chats/{id}/users": {
+      "/chats/direct": {
         "post": {
           "tags": [
             "chats"
           ],
-          "summary": "Add users to a chat",
-          "description": "Adds specified users to an existing chat",
-          "parameters": [
-            {
-              "name": "id",
-              "in": "path",
-              "description": "Chat ID",
-              "required": true,
-              "schema": {
-                "type": "string"
-              }
-            }
-          ],
+          "summary": "Get or create a direct chat",
+          "description": "Returns the individual chat between the caller and the given user, creating it only when it does not exist yet",
           "requestBody": {
-            "description": "List of user IDs to add",
+            "description": "The other user of the chat",
             "content": {
               "application/json": {
                 "schema": {
-                  "$ref": "#/components/schemas/dtos.AddUsersRequest"
+                  "$ref": "#/components/schemas/dtos.DirectChatRequest"
                 }
               }
             },
@@ -414,10 +412,17 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
+                    "$ref": "#/components/schemas/models.Chat"
+                  }
+                }
+              }
+            },
+            "201": {
+              "description": "Created",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Chat"
                   }
                 }
               }
@@ -432,8 +437,8 @@ const docTemplate = `{
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
@@ -441,56 +446,56 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
           "security": [
             {
               "Bearer": []
             }
           ],
-          "x-codegen-request-body-name": "users"
+          "x-codegen-request-body-name": "user"
         }
       },
-      "/friendships": {
+      "/chats/meeyok": {
         "post": {
           "tags": [
-            "friendship"
-          ],
-          "summary": "Send a friend request",
-          "description": "Sends a friend request from one user to another",
-          "parameters": [
-            {
-              "name": "id1",
-              "in": "query",
-              "description": "User ID of the requester",
-              "required": true,
-              "schema": {
-                "type": "string"
-              }
-            },
-            {
-              "name": "id2",
-              "in": "query",
-              "description": "User ID of the recipient",
-              "required": true,
-              "schema": {
-                "type": "string"
-              }
-            }
+            "chats"
           ],
+          "summary": "Get or create the chat with Meeyok",
+          "description": "Returns the chat of the caller with Meeyok AI, where every message is answered by the AI, creating it only when it does not exist yet",
           "responses": {
             "200": {
               "description": "OK",
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Friendship"
+                    "$ref": "#/components/schemas/models.Chat"
                   }
                 }
               }
             },
-            "400": {
-              "description": "Bad Request",
+            "201": {
+              "description": "Created",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Chat"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
@@ -517,22 +522,30 @@ const docTemplate = `{
           ]
         }
       },
-      "/friendships/accept/{userId}": {
-        "patch": {
+      "/chats/mentions": {
+        "get": {
           "tags": [
-            "friendship"
+            "chats"
           ],
-          "summary": "Accept a friend request",
-          "description": "Accepts a pending friend request between the current user and the specified user",
+          "summary": "Get unread mentions",
+          "description": "Retrieves the messages mentioning the caller that the caller has not read yet, across all of their chats, newest first",
           "parameters": [
             {
-              "name": "userId",
-              "in": "path",
-              "description": "Friend's user ID",
-              "required": true,
+              "name": "before",
+              "in": "query",
+              "description": "Only messages older than this message ID",
               "schema": {
                 "type": "string"
               }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of messages",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
             }
           ],
           "responses": {
@@ -541,7 +554,7 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Friendship"
+                    "$ref": "#/components/schemas/models.MessagePage"
                   }
                 }
               }
@@ -574,18 +587,18 @@ const docTemplate = `{
           ]
         }
       },
-      "/friendships/reject/{userId}": {
-        "patch": {
+      "/chats/user/{type}": {
+        "get": {
           "tags": [
-            "friendship"
+            "chats"
           ],
-          "summary": "Reject a friend request",
-          "description": "Rejects a pending friend request between the current user and the specified user",
+          "summary": "Get user chats based on type",
+          "description": "Retrieves chats based on the given type (group, friend, non-friend, meeyok) with the unread counter and read marker of the caller",
           "parameters": [
             {
-              "name": "userId",
+              "name": "type",
               "in": "path",
-              "description": "Friend's user ID",
+              "description": "Type of chat (group, friend, non-friend, meeyok)",
               "required": true,
               "schema": {
                 "type": "string"
@@ -598,7 +611,10 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Friendship"
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.Chat"
+                    }
                   }
                 }
               }
@@ -631,22 +647,40 @@ const docTemplate = `{
           ]
         }
       },
-      "/friendships/{status}": {
+      "/chats/{id}": {
         "get": {
           "tags": [
-            "friendship"
+            "chats"
           ],
-          "summary": "Get list of friends with status filter",
-          "description": "Returns a list of users who are friends or pending with the given user",
+          "summary": "Get a specific chat by ID",
+          "description": "Retrieves a chat with messages based on the provided ID",
           "parameters": [
             {
-              "name": "status",
+              "name": "id",
               "in": "path",
-              "description": "Friendship status: accepted, pending, or rejected",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
               }
+            },
+            {
+              "name": "page",
+              "in": "query",
+              "description": "Page number for pagination",
+              "schema": {
+                "type": "integer",
+                "default": 1
+              }
+            },
+            {
+              "name": "num-message",
+              "in": "query",
+              "description": "Number of messages per page, up to 100",
+              "schema": {
+                "type": "integer",
+                "default": 10
+              }
             }
           ],
           "responses": {
@@ -655,10 +689,7 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "array",
-                    "items": {
-                      "$ref": "#/components/schemas/models.User"
-                    }
+                    "$ref": "#/components/schemas/models.Chat"
                   }
                 }
               }
@@ -673,8 +704,8 @@ const docTemplate = `{
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
@@ -682,32 +713,13 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
-          "security": [
-            {
-              "Bearer": []
-            }
-          ]
-        }
-      },
-      "/posts": {
-        "get": {
-          "tags": [
-            "posts"
-          ],
-          "summary": "Get all posts",
-          "description": "Retrieves all posts from the database",
-          "responses": {
-            "200": {
-              "description": "OK",
+            },
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "array",
-                    "items": {
-                      "$ref": "#/components/schemas/models.Post"
-                    }
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
@@ -729,18 +741,29 @@ const docTemplate = `{
             }
           ]
         },
-        "post": {
+        "put": {
           "tags": [
-            "posts"
+            "chats"
+          ],
+          "summary": "Update a chat",
+          "description": "Updates the details of an existing chat, only the owner and admins can rename a group chat",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
           ],
-          "summary": "Create a new post",
-          "description": "Creates a new post and associates it with the authenticated user",
           "requestBody": {
-            "description": "Post details",
+            "description": "Updated chat details",
             "content": {
               "application/json": {
                 "schema": {
-                  "$ref": "#/components/schemas/dtos.CreatePostRequest"
+                  "$ref": "#/components/schemas/dtos.UpdateChatRequest"
                 }
               }
             },
@@ -752,7 +775,10 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Post"
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
                   }
                 }
               }
@@ -767,6 +793,26 @@ const docTemplate = `{
                 }
               }
             },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
             "500": {
               "description": "Internal Server Error",
               "content": {
@@ -783,20 +829,19 @@ const docTemplate = `{
               "Bearer": []
             }
           ],
-          "x-codegen-request-body-name": "post"
-        }
-      },
-      "/posts/{id}": {
-        "get": {
+          "x-codegen-request-body-name": "chat"
+        },
+        "delete": {
           "tags": [
-            "posts"
+            "chats"
           ],
-          "summary": "Get a post by ID",
+          "summary": "Delete a chat",
+          "description": "Deletes a chat by its ID and tells its members with a chat_deleted event, only the owner can delete a group chat",
           "parameters": [
             {
               "name": "id",
               "in": "path",
-              "description": "Post ID",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
@@ -809,96 +854,73 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.Post"
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
                   }
                 }
               }
             },
-            "400": {
-              "description": "Bad Request",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
             }
-          }
-        },
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/admins/{userId}": {
         "put": {
           "tags": [
-            "posts"
+            "chats"
           ],
-          "summary": "Update a post",
+          "summary": "Promote a member to admin",
+          "description": "Makes a member of a group chat an admin, only the owner and admins can promote",
           "parameters": [
             {
               "name": "id",
               "in": "path",
-              "description": "Post ID",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
               }
-            }
-          ],
-          "requestBody": {
-            "description": "Updated content",
-            "content": {
-              "application/json": {
-                "schema": {
-                  "type": "object",
-                  "additionalProperties": {
-                    "type": "string"
-                  }
-                }
-              }
-            },
-            "required": true
-          },
-          "responses": {
-            "200": {
-              "description": "OK",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
-                  }
-                }
-              }
             },
-            "400": {
-              "description": "Bad Request",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
-                  }
-                }
-              }
-            }
-          },
-          "x-codegen-request-body-name": "post"
-        },
-        "delete": {
-          "tags": [
-            "posts"
-          ],
-          "summary": "Delete a post",
-          "parameters": [
             {
-              "name": "id",
+              "name": "userId",
               "in": "path",
-              "description": "Post ID",
+              "description": "User ID of the member",
               "required": true,
               "schema": {
                 "type": "string"
@@ -924,40 +946,13 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
-                  }
-                }
-              }
-            }
-          }
-        }
-      },
-      "/users": {
-        "get": {
-          "tags": [
-            "users"
-          ],
-          "summary": "List all users",
-          "description": "Retrieves a list of all users",
-          "responses": {
-            "200": {
-              "description": "OK",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "type": "array",
-                    "items": {
-                      "$ref": "#/components/schemas/models.User"
-                    }
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
@@ -965,35 +960,9 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
-          "security": [
-            {
-              "Bearer": []
-            }
-          ]
-        }
-      },
-      "/users/me": {
-        "get": {
-          "tags": [
-            "users"
-          ],
-          "summary": "Get current user details",
-          "description": "Retrieves the details of the authenticated user",
-          "responses": {
-            "200": {
-              "description": "OK",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "$ref": "#/components/schemas/models.User"
-                  }
-                }
-              }
             },
-            "401": {
-              "description": "Unauthorized",
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
@@ -1018,20 +987,27 @@ const docTemplate = `{
               "Bearer": []
             }
           ]
-        }
-      },
-      "/users/username/{username}": {
-        "get": {
+        },
+        "delete": {
           "tags": [
-            "users"
+            "chats"
           ],
-          "summary": "Get user by username",
-          "description": "Retrieves a user's details by their username",
+          "summary": "Demote an admin to member",
+          "description": "Makes an admin of a group chat a member again, only the owner can demote other admins but an admin can always step down",
           "parameters": [
             {
-              "name": "username",
+              "name": "id",
               "in": "path",
-              "description": "Username",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "userId",
+              "in": "path",
+              "description": "User ID of the admin",
               "required": true,
               "schema": {
                 "type": "string"
@@ -1044,13 +1020,16 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.User"
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
                   }
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "400": {
+              "description": "Bad Request",
               "content": {
                 "application/json": {
                   "schema": {
@@ -1058,40 +1037,23 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
-          "security": [
-            {
-              "Bearer": []
-            }
-          ]
-        }
-      },
-      "/users/{id}": {
-        "get": {
-          "tags": [
-            "users"
-          ],
-          "summary": "Get user by ID",
-          "description": "Retrieves a specific user by their ID",
-          "parameters": [
-            {
-              "name": "id",
-              "in": "path",
-              "description": "User ID",
-              "required": true,
-              "schema": {
-                "type": "string"
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
               }
-            }
-          ],
-          "responses": {
-            "200": {
-              "description": "OK",
+            },
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
-                    "$ref": "#/components/schemas/models.User"
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
@@ -1112,18 +1074,20 @@ const docTemplate = `{
               "Bearer": []
             }
           ]
-        },
+        }
+      },
+      "/chats/{id}/ai": {
         "put": {
           "tags": [
-            "users"
+            "chats"
           ],
-          "summary": "Update user details",
-          "description": "Updates an existing user's information",
+          "summary": "Set up Meeyok AI in a chat",
+          "description": "Turns Meeyok AI on or off in the chat and sets the persona it follows, only the owner and admins of a group can change them. Fields left out keep their value.",
           "parameters": [
             {
               "name": "id",
               "in": "path",
-              "description": "User ID",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
@@ -1131,11 +1095,11 @@ const docTemplate = `{
             }
           ],
           "requestBody": {
-            "description": "Updated user details",
+            "description": "AI settings",
             "content": {
               "application/json": {
                 "schema": {
-                  "$ref": "#/components/schemas/models.User"
+                  "$ref": "#/components/schemas/dtos.SetAISettingsRequest"
                 }
               }
             },
@@ -1147,10 +1111,7 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
+                    "$ref": "#/components/schemas/models.Chat"
                   }
                 }
               }
@@ -1165,8 +1126,8 @@ const docTemplate = `{
                 }
               }
             },
-            "500": {
-              "description": "Internal Server Error",
+            "403": {
+              "description": "Forbidden",
               "content": {
                 "application/json": {
                   "schema": {
@@ -1174,48 +1135,9 @@ const docTemplate = `{
                   }
                 }
               }
-            }
-          },
-          "security": [
-            {
-              "Bearer": []
-            }
-          ],
-          "x-codegen-request-body-name": "user"
-        },
-        "delete": {
-          "tags": [
-            "users"
-          ],
-          "summary": "Delete a user",
-          "description": "Removes a user from the system",
-          "parameters": [
-            {
-              "name": "id",
-              "in": "path",
-              "description": "User ID",
-              "required": true,
-              "schema": {
-                "type": "string"
-              }
-            }
-          ],
-          "responses": {
-            "200": {
-              "description": "OK",
-              "content": {
-                "application/json": {
-                  "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
-                  }
-                }
-              }
             },
-            "400": {
-              "description": "Bad Request",
+            "404": {
+              "description": "Not Found",
               "content": {
                 "application/json": {
                   "schema": {
@@ -1239,21 +1161,22 @@ const docTemplate = `{
             {
               "Bearer": []
             }
-          ]
+          ],
+          "x-codegen-request-body-name": "settings"
         }
       },
-      "/users/{id}/username": {
-        "patch": {
+      "/chats/{id}/attachments": {
+        "post": {
           "tags": [
-            "users"
+            "attachments"
           ],
-          "summary": "Update username",
-          "description": "Updates the username for a specific user",
+          "summary": "Upload an attachment",
+          "description": "Uploads a file into the chat, send its id in the attachments of a send_message event to share it. Images get a thumbnail.",
           "parameters": [
             {
               "name": "id",
               "in": "path",
-              "description": "User ID",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
@@ -1261,11 +1184,20 @@ const docTemplate = `{
             }
           ],
           "requestBody": {
-            "description": "New username",
             "content": {
-              "application/json": {
+              "multipart/form-data": {
                 "schema": {
-                  "$ref": "#/components/schemas/models.User"
+                  "required": [
+                    "file"
+                  ],
+                  "type": "object",
+                  "properties": {
+                    "file": {
+                      "type": "string",
+                      "description": "File to upload, at most 10MB",
+                      "format": "binary"
+                    }
+                  }
                 }
               }
             },
@@ -1277,10 +1209,7 @@ const docTemplate = `{
               "content": {
                 "application/json": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "string"
-                    }
+                    "$ref": "#/components/schemas/models.Attachment"
                   }
                 }
               }
@@ -1295,7 +1224,47 @@ const docTemplate = `{
                 }
               }
             },
-            "500": {
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "413": {
+              "description": "Request Entity Too Large",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "415": {
+              "description": "Unsupported Media Type",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
               "description": "Internal Server Error",
               "content": {
                 "application/json": {
@@ -1310,30 +1279,63 @@ const docTemplate = `{
             {
               "Bearer": []
             }
-          ],
-          "x-codegen-request-body-name": "user"
+          ]
         }
       },
-      "/ws/clients": {
+      "/chats/{id}/attachments/{attachmentId}": {
         "get": {
           "tags": [
-            "websocket"
+            "attachments"
+          ],
+          "summary": "Download an attachment",
+          "description": "Downloads the file of an attachment of the chat",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "attachmentId",
+              "in": "path",
+              "description": "Attachment ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
           ],
-          "summary": "List active WebSocket clients",
-          "description": "Retrieves a list of currently connected WebSocket clients",
           "responses": {
             "200": {
               "description": "OK",
               "content": {
-                "application/json": {
+                "application/octet-stream": {
                   "schema": {
-                    "type": "object",
-                    "additionalProperties": {
-                      "type": "array",
-                      "items": {
-                        "type": "string"
-                      }
-                    }
+                    "type": "file"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/octet-stream": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/octet-stream": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
                   }
                 }
               }
@@ -1341,7 +1343,7 @@ const docTemplate = `{
             "500": {
               "description": "Internal Server Error",
               "content": {
-                "application/json": {
+                "application/octet-stream": {
                   "schema": {
                     "$ref": "#/components/schemas/models.HTTPError"
                   }
@@ -1356,13 +1358,100 @@ const docTemplate = `{
           ]
         }
       },
-      "/ws/init": {
+      "/chats/{id}/attachments/{attachmentId}/thumbnail": {
         "get": {
           "tags": [
-            "websocket"
+            "attachments"
+          ],
+          "summary": "Download the thumbnail of an attachment",
+          "description": "Downloads the JPEG thumbnail of an image attachment of the chat",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "attachmentId",
+              "in": "path",
+              "description": "Attachment ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "image/jpeg": {
+                  "schema": {
+                    "type": "file"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "image/jpeg": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "image/jpeg": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "image/jpeg": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/leave": {
+        "post": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Leave a group chat",
+          "description": "Takes the caller out of a group chat. An owner leaving hands the chat over to an admin, or to a member when there are no admins, and the last member leaving deletes the chat.",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
           ],
-          "summary": "Initialize WebSocket connection",
-          "description": "Prepares for WebSocket connection by checking existing client",
           "responses": {
             "200": {
               "description": "OK",
@@ -1387,6 +1476,26 @@ const docTemplate = `{
                 }
               }
             },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
             "500": {
               "description": "Internal Server Error",
               "content": {
@@ -1405,29 +1514,58 @@ const docTemplate = `{
           ]
         }
       },
-      "/ws/{userID}": {
+      "/chats/{id}/messages": {
         "get": {
           "tags": [
-            "websocket"
+            "chats"
           ],
-          "summary": "Establish WebSocket connection",
-          "description": "Upgrades HTTP connection to WebSocket for real-time communication",
+          "summary": "Get messages of a chat",
+          "description": "Retrieves a page of messages of the chat in chronological order, use the id of the first message as the before cursor to load older messages",
           "parameters": [
             {
-              "name": "userID",
+              "name": "id",
               "in": "path",
-              "description": "User ID",
+              "description": "Chat ID",
               "required": true,
               "schema": {
                 "type": "string"
               }
+            },
+            {
+              "name": "before",
+              "in": "query",
+              "description": "Only messages older than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "after",
+              "in": "query",
+              "description": "Only messages newer than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of messages",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
             }
           ],
           "responses": {
-            "101": {
-              "description": "Switching Protocols",
+            "200": {
+              "description": "OK",
               "content": {
-  
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.MessagePage"
+                  }
+                }
               }
             },
             "400": {
@@ -1440,6 +1578,26 @@ const docTemplate = `{
                 }
               }
             },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
             "500": {
               "description": "Internal Server Error",
               "content": {
@@ -1457,103 +1615,3063 @@ const docTemplate = `{
             }
           ]
         }
-      }
-    },
-    "components": {
-      "schemas": {
-        "dtos.AddUsersRequest": {
-          "required": [
-            "users"
+      },
+      "/chats/{id}/messages/{messageId}": {
+        "delete": {
+          "tags": [
+            "chats"
           ],
-          "type": "object",
-          "properties": {
-            "users": {
-              "type": "array",
-              "example": [
-                "user123",
-                "user456"
-              ],
-              "items": {
+          "summary": "Delete a message",
+          "description": "Deletes a message for the caller only, or for everyone when the caller sent it",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
                 "type": "string"
               }
-            }
-          }
-        },
-        "dtos.CreateChatRequest": {
-          "required": [
-            "name",
-            "type",
-            "users"
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "scope",
+              "in": "query",
+              "description": "me or everyone",
+              "schema": {
+                "type": "string",
+                "default": "me"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "patch": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Edit a message",
+          "description": "Changes the text of a message sent by the caller, the previous text is kept in its edit history",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "New text of the message",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.EditMessageRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Message"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "message"
+        }
+      },
+      "/chats/{id}/messages/{messageId}/reactions": {
+        "post": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "React to a message",
+          "description": "Adds a reaction of the caller to a message",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "Emoji to react with",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.ReactionRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Message"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "reaction"
+        }
+      },
+      "/chats/{id}/messages/{messageId}/reactions/{emoji}": {
+        "delete": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Remove a reaction from a message",
+          "description": "Takes a reaction of the caller back",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "emoji",
+              "in": "path",
+              "description": "Emoji of the reaction",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Message"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/messages/{messageId}/thread": {
+        "get": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Get a thread of a chat",
+          "description": "Retrieves the root message of a thread with a page of its replies in chronological order",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Root message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "before",
+              "in": "query",
+              "description": "Only replies older than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "after",
+              "in": "query",
+              "description": "Only replies newer than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of replies",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.ThreadPage"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/owner": {
+        "put": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Transfer the ownership of a group chat",
+          "description": "Hands a group chat over to another member, only the owner can do this and stays on as an admin",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "New owner",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.TransferOwnershipRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "owner"
+        }
+      },
+      "/chats/{id}/pins": {
+        "get": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "List the pinned messages of a chat",
+          "description": "Retrieves the pinned messages of the chat, the latest pin first",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.PinnedMessage"
+                    }
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/pins/{messageId}": {
+        "put": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Pin a message",
+          "description": "Pins a message of the chat, only the owner and admins of a group can pin. Pinning a pinned message does nothing.",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Pin"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "409": {
+              "description": "Conflict",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "delete": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Unpin a message",
+          "description": "Unpins a message of the chat, only the owner and admins of a group can unpin",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "messageId",
+              "in": "path",
+              "description": "Message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/receipts": {
+        "get": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Get message receipts of a chat",
+          "description": "Retrieves the delivered and read receipts for a range of messages in the chat",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "before",
+              "in": "query",
+              "description": "Only messages older than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "after",
+              "in": "query",
+              "description": "Only messages newer than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of messages",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.MessageReceipts"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/chats/{id}/retention": {
+        "put": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Set disappearing messages",
+          "description": "Sets how long new messages of the chat live before they disappear, only the owner and admins of a group can change it",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "Message lifetime",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.SetRetentionRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "retention"
+        }
+      },
+      "/chats/{id}/users": {
+        "post": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Add users to a chat",
+          "description": "Adds specified users to an existing group chat, only its owner and admins can add users",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "List of user IDs to add",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.AddUsersRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "users"
+        }
+      },
+      "/chats/{id}/users/{userId}": {
+        "delete": {
+          "tags": [
+            "chats"
+          ],
+          "summary": "Remove a member from a group chat",
+          "description": "Removes a member from a group chat, only the owner and admins can remove members and only the owner can remove admins",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Chat ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "userId",
+              "in": "path",
+              "description": "User ID of the member",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/friendships": {
+        "post": {
+          "tags": [
+            "friendship"
+          ],
+          "summary": "Send a friend request",
+          "description": "Sends a friend request from one user to another",
+          "parameters": [
+            {
+              "name": "id1",
+              "in": "query",
+              "description": "User ID of the requester",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "id2",
+              "in": "query",
+              "description": "User ID of the recipient",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Friendship"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/friendships/accept/{userId}": {
+        "patch": {
+          "tags": [
+            "friendship"
+          ],
+          "summary": "Accept a friend request",
+          "description": "Accepts a pending friend request between the current user and the specified user",
+          "parameters": [
+            {
+              "name": "userId",
+              "in": "path",
+              "description": "Friend's user ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Friendship"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/friendships/reject/{userId}": {
+        "patch": {
+          "tags": [
+            "friendship"
+          ],
+          "summary": "Reject a friend request",
+          "description": "Rejects a pending friend request between the current user and the specified user",
+          "parameters": [
+            {
+              "name": "userId",
+              "in": "path",
+              "description": "Friend's user ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Friendship"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/friendships/{status}": {
+        "get": {
+          "tags": [
+            "friendship"
+          ],
+          "summary": "Get list of friends with status filter",
+          "description": "Returns a list of users who are friends or pending with the given user",
+          "parameters": [
+            {
+              "name": "status",
+              "in": "path",
+              "description": "Friendship status: accepted, pending, or rejected",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.User"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/posts": {
+        "get": {
+          "tags": [
+            "posts"
+          ],
+          "summary": "Get all posts",
+          "description": "Retrieves all posts from the database",
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.Post"
+                    }
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "post": {
+          "tags": [
+            "posts"
+          ],
+          "summary": "Create a new post",
+          "description": "Creates a new post and associates it with the authenticated user",
+          "requestBody": {
+            "description": "Post details",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.CreatePostRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Post"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "post"
+        }
+      },
+      "/posts/{id}": {
+        "get": {
+          "tags": [
+            "posts"
+          ],
+          "summary": "Get a post by ID",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Post ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.Post"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        },
+        "put": {
+          "tags": [
+            "posts"
+          ],
+          "summary": "Update a post",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Post ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "Updated content",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "additionalProperties": {
+                    "type": "string"
+                  }
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "x-codegen-request-body-name": "post"
+        },
+        "delete": {
+          "tags": [
+            "posts"
+          ],
+          "summary": "Delete a post",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Post ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      },
+      "/scheduled": {
+        "get": {
+          "tags": [
+            "scheduled"
+          ],
+          "summary": "List scheduled messages",
+          "description": "Lists the messages the caller scheduled that were not sent yet in the order they are due, failed ones included with their error",
+          "parameters": [
+            {
+              "name": "chat",
+              "in": "query",
+              "description": "Only messages scheduled in this chat",
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.ScheduledMessage"
+                    }
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "post": {
+          "tags": [
+            "scheduled"
+          ],
+          "summary": "Schedule a message",
+          "description": "Stores a message to be sent into the chat at sendAt, as if the caller sent it then",
+          "requestBody": {
+            "description": "Message to schedule",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.ScheduleMessageRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "201": {
+              "description": "Created",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.ScheduledMessage"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "message"
+        }
+      },
+      "/scheduled/{id}": {
+        "delete": {
+          "tags": [
+            "scheduled"
+          ],
+          "summary": "Cancel a scheduled message",
+          "description": "Cancels a message that was not sent yet, or dismisses one that failed to send",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Scheduled message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "patch": {
+          "tags": [
+            "scheduled"
+          ],
+          "summary": "Edit a scheduled message",
+          "description": "Changes the text or the time of a message that was not sent yet",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "Scheduled message ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "Fields to change",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/dtos.EditScheduledMessageRequest"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.ScheduledMessage"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "message"
+        }
+      },
+      "/search": {
+        "get": {
+          "tags": [
+            "search"
+          ],
+          "summary": "Search messages and chats",
+          "description": "Searches the text of the messages and the names of the chats of the caller. Snippets are HTML escaped with the matches wrapped in <mark> tags, use nextCursor as the before cursor of the next page.",
+          "parameters": [
+            {
+              "name": "q",
+              "in": "query",
+              "description": "Text to search for",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "chat",
+              "in": "query",
+              "description": "Only search this chat",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "from",
+              "in": "query",
+              "description": "Only messages sent by this user ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "since",
+              "in": "query",
+              "description": "Only messages sent at or after this RFC 3339 time",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "until",
+              "in": "query",
+              "description": "Only messages sent before this RFC 3339 time",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "before",
+              "in": "query",
+              "description": "Only messages older than this message ID",
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "limit",
+              "in": "query",
+              "description": "Number of messages",
+              "schema": {
+                "type": "integer",
+                "default": 20
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.SearchResult"
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/users": {
+        "get": {
+          "tags": [
+            "users"
+          ],
+          "summary": "List all users",
+          "description": "Retrieves a list of all users",
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "array",
+                    "items": {
+                      "$ref": "#/components/schemas/models.User"
+                    }
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/users/me": {
+        "get": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Get current user details",
+          "description": "Retrieves the details of the authenticated user",
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.User"
+                  }
+                }
+              }
+            },
+            "401": {
+              "description": "Unauthorized",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/users/username/{username}": {
+        "get": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Get user by username",
+          "description": "Retrieves a user's details by their username",
+          "parameters": [
+            {
+              "name": "username",
+              "in": "path",
+              "description": "Username",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.User"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/users/{id}": {
+        "get": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Get user by ID",
+          "description": "Retrieves a specific user by their ID",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "User ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.User"
+                  }
+                }
+              }
+            },
+            "404": {
+              "description": "Not Found",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        },
+        "put": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Update user details",
+          "description": "Updates an existing user's information",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "User ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "Updated user details",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/models.User"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "user"
+        },
+        "delete": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Delete a user",
+          "description": "Removes a user from the system",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "User ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/users/{id}/username": {
+        "patch": {
+          "tags": [
+            "users"
+          ],
+          "summary": "Update username",
+          "description": "Updates the username for a specific user",
+          "parameters": [
+            {
+              "name": "id",
+              "in": "path",
+              "description": "User ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "description": "New username",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/models.User"
+                }
+              }
+            },
+            "required": true
+          },
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ],
+          "x-codegen-request-body-name": "user"
+        }
+      },
+      "/ws/clients": {
+        "get": {
+          "tags": [
+            "websocket"
+          ],
+          "summary": "List active WebSocket clients",
+          "description": "Retrieves a list of currently connected WebSocket clients, only for admins",
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "array",
+                      "items": {
+                        "type": "string"
+                      }
+                    }
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/ws/init": {
+        "get": {
+          "tags": [
+            "websocket"
+          ],
+          "summary": "Initialize WebSocket connection",
+          "description": "Prepares for WebSocket connection by checking existing client, and issues a one-time ticket to open it with",
+          "responses": {
+            "200": {
+              "description": "OK",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "type": "object",
+                    "additionalProperties": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "500": {
+              "description": "Internal Server Error",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          },
+          "security": [
+            {
+              "Bearer": []
+            }
+          ]
+        }
+      },
+      "/ws/{userID}": {
+        "get": {
+          "tags": [
+            "websocket"
+          ],
+          "summary": "Establish WebSocket connection",
+          "description": "Upgrades HTTP connection to WebSocket for real-time communication, the user comes from the ticket issued by /ws/init",
+          "parameters": [
+            {
+              "name": "userID",
+              "in": "path",
+              "description": "User ID",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "ticket",
+              "in": "query",
+              "description": "One-time ticket from /ws/init",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "name": "last_seq",
+              "in": "query",
+              "description": "Last sequence the client has seen, the events after it are replayed before live ones",
+              "schema": {
+                "type": "integer"
+              }
+            }
+          ],
+          "responses": {
+            "101": {
+              "description": "Switching Protocols"
+            },
+            "400": {
+              "description": "Bad Request",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "401": {
+              "description": "Unauthorized",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            },
+            "403": {
+              "description": "Forbidden",
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/models.HTTPError"
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "components": {
+      "schemas": {
+        "dtos.AddUsersRequest": {
+          "required": [
+            "users"
+          ],
+          "type": "object",
+          "properties": {
+            "users": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              },
+              "example": [
+                "user123",
+                "user456"
+              ]
+            }
+          }
+        },
+        "dtos.CreateChatRequest": {
+          "required": [
+            "name",
+            "type",
+            "users"
+          ],
+          "type": "object",
+          "properties": {
+            "name": {
+              "type": "string",
+              "example": "Team Discussion"
+            },
+            "type": {
+              "type": "string",
+              "enum": [
+                "Individual",
+                "Group"
+              ],
+              "example": "Group"
+            },
+            "users": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              },
+              "example": [
+                "user123",
+                "user456"
+              ]
+            }
+          }
+        },
+        "dtos.CreatePostRequest": {
+          "required": [
+            "content",
+            "title"
+          ],
+          "type": "object",
+          "properties": {
+            "content": {
+              "type": "string",
+              "example": "Content of the post"
+            },
+            "title": {
+              "type": "string",
+              "example": "Title of the post"
+            }
+          }
+        },
+        "dtos.DirectChatRequest": {
+          "required": [
+            "userId"
+          ],
+          "type": "object",
+          "properties": {
+            "userId": {
+              "type": "string",
+              "example": "user456"
+            }
+          }
+        },
+        "dtos.EditMessageRequest": {
+          "required": [
+            "message"
+          ],
+          "type": "object",
+          "properties": {
+            "message": {
+              "type": "string",
+              "example": "Hello, world"
+            }
+          }
+        },
+        "dtos.EditScheduledMessageRequest": {
+          "type": "object",
+          "properties": {
+            "message": {
+              "type": "string",
+              "example": "Happy birthday!!"
+            },
+            "sendAt": {
+              "type": "string",
+              "example": "2025-01-01T10:00:00Z"
+            }
+          }
+        },
+        "dtos.ReactionRequest": {
+          "required": [
+            "emoji"
+          ],
+          "type": "object",
+          "properties": {
+            "emoji": {
+              "type": "string",
+              "example": "👍"
+            }
+          }
+        },
+        "dtos.ScheduleMessageRequest": {
+          "required": [
+            "chatId",
+            "sendAt"
+          ],
+          "type": "object",
+          "properties": {
+            "attachments": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              },
+              "example": [
+                "64b7f0c2e4b0a1a2b3c4d5e8"
+              ]
+            },
+            "chatId": {
+              "type": "string",
+              "example": "64b7f0c2e4b0a1a2b3c4d5e6"
+            },
+            "message": {
+              "type": "string",
+              "example": "Happy birthday!"
+            },
+            "replyTo": {
+              "type": "string",
+              "example": "64b7f0c2e4b0a1a2b3c4d5e7"
+            },
+            "sendAt": {
+              "type": "string",
+              "example": "2025-01-01T09:00:00Z"
+            }
+          }
+        },
+        "dtos.SetAISettingsRequest": {
+          "type": "object",
+          "properties": {
+            "enabled": {
+              "type": "boolean",
+              "example": true
+            },
+            "persona": {
+              "maxLength": 2000,
+              "type": "string",
+              "example": "You are a cheerful assistant who answers in Thai"
+            }
+          }
+        },
+        "dtos.SetRetentionRequest": {
+          "required": [
+            "ttl"
+          ],
+          "type": "object",
+          "properties": {
+            "ttl": {
+              "type": "string",
+              "enum": [
+                "off",
+                "24h",
+                "7d"
+              ],
+              "example": "24h"
+            }
+          }
+        },
+        "dtos.TransferOwnershipRequest": {
+          "required": [
+            "userId"
+          ],
+          "type": "object",
+          "properties": {
+            "userId": {
+              "type": "string",
+              "example": "user456"
+            }
+          }
+        },
+        "dtos.UpdateChatRequest": {
+          "required": [
+            "name"
           ],
           "type": "object",
           "properties": {
             "name": {
               "type": "string",
-              "example": "Team Discussion"
+              "example": "Team Discussion"
+            }
+          }
+        },
+        "models.AIRequest": {
+          "type": "object",
+          "properties": {
+            "attempts": {
+              "type": "integer",
+              "description": "Attempts counts the times the request was published, SentAt is the last one"
+            },
+            "chatId": {
+              "type": "string"
+            },
+            "createdAt": {
+              "type": "string"
+            },
+            "error": {
+              "type": "string"
+            },
+            "id": {
+              "type": "string"
+            },
+            "replyMessageId": {
+              "type": "string"
+            },
+            "requestedBy": {
+              "type": "string"
+            },
+            "sentAt": {
+              "type": "string"
+            },
+            "status": {
+              "type": "string"
+            },
+            "triggerMessageId": {
+              "type": "string"
+            },
+            "updatedAt": {
+              "type": "string"
+            }
+          }
+        },
+        "models.Attachment": {
+          "type": "object",
+          "properties": {
+            "chatId": {
+              "type": "string"
+            },
+            "contentType": {
+              "type": "string"
+            },
+            "createdAt": {
+              "type": "string"
+            },
+            "fileName": {
+              "type": "string"
+            },
+            "hasThumbnail": {
+              "type": "boolean"
+            },
+            "height": {
+              "type": "integer"
+            },
+            "id": {
+              "type": "string"
+            },
+            "messageId": {
+              "type": "string",
+              "description": "MessageID is set once the attachment is sent, an attachment belongs to a single message"
+            },
+            "size": {
+              "type": "integer"
+            },
+            "uploadedBy": {
+              "type": "string"
+            },
+            "width": {
+              "type": "integer",
+              "description": "Width and Height are only known for images"
+            }
+          }
+        },
+        "models.Chat": {
+          "type": "object",
+          "properties": {
+            "admins": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            "aiDisabled": {
+              "type": "boolean",
+              "description": "AIDisabled turns Meeyok AI off in the chat, it is on unless turned off"
+            },
+            "aiPersona": {
+              "type": "string",
+              "description": "AIPersona is the system prompt Meeyok AI follows when it replies in the chat"
+            },
+            "id": {
+              "type": "string"
+            },
+            "lastReadMessageId": {
+              "type": "string"
+            },
+            "messageTtl": {
+              "type": "integer",
+              "description": "MessageTTL is how many seconds new messages live before they disappear, zero keeps them"
+            },
+            "messages": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Message"
+              }
+            },
+            "name": {
+              "type": "string"
+            },
+            "owner": {
+              "type": "string",
+              "description": "Owner and Admins manage a group chat, every other user is a member"
+            },
+            "pins": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Pin"
+              },
+              "description": "Pins are the pinned messages of the chat in the order they were pinned"
             },
             "type": {
-              "type": "string",
-              "example": "Group",
-              "enum": [
-                "Individual",
-                "Group"
-              ]
+              "type": "string"
+            },
+            "unreadCount": {
+              "type": "integer",
+              "description": "UnreadCount and LastReadMessageID are filled in for the user listing the chats"
+            },
+            "updatedAt": {
+              "type": "string"
             },
             "users": {
               "type": "array",
-              "example": [
-                "user123",
-                "user456"
-              ],
               "items": {
                 "type": "string"
               }
             }
           }
         },
-        "dtos.CreatePostRequest": {
-          "required": [
-            "content",
-            "title"
-          ],
+        "models.ChatSearchHit": {
           "type": "object",
           "properties": {
-            "content": {
-              "type": "string",
-              "example": "Content of the post"
+            "chat": {
+              "$ref": "#/components/schemas/models.Chat"
             },
-            "title": {
-              "type": "string",
-              "example": "Title of the post"
+            "snippet": {
+              "type": "string"
             }
           }
         },
-        "models.Chat": {
+        "models.DeadLetter": {
           "type": "object",
           "properties": {
-            "id": {
+            "body": {
               "type": "string"
             },
-            "messages": {
-              "type": "array",
-              "items": {
-                "$ref": "#/components/schemas/models.Message"
-              }
+            "correlationId": {
+              "type": "string"
             },
-            "name": {
+            "createdAt": {
               "type": "string"
             },
-            "type": {
+            "error": {
               "type": "string"
             },
-            "updatedAt": {
+            "id": {
               "type": "string"
             },
-            "users": {
+            "queue": {
+              "type": "string",
+              "description": "Queue is the queue the message is replayed into"
+            },
+            "replayedAt": {
+              "type": "string"
+            }
+          }
+        },
+        "models.DeadLetterPage": {
+          "type": "object",
+          "properties": {
+            "deadLetters": {
               "type": "array",
               "items": {
-                "type": "string"
+                "$ref": "#/components/schemas/models.DeadLetter"
               }
+            },
+            "hasMore": {
+              "type": "boolean"
             }
           }
         },
@@ -1592,19 +4710,194 @@ const docTemplate = `{
             }
           }
         },
+        "models.Mention": {
+          "type": "object",
+          "properties": {
+            "length": {
+              "type": "integer"
+            },
+            "offset": {
+              "type": "integer"
+            },
+            "userId": {
+              "type": "string"
+            },
+            "username": {
+              "type": "string"
+            }
+          }
+        },
         "models.Message": {
           "type": "object",
           "properties": {
+            "attachments": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Attachment"
+              }
+            },
+            "chatId": {
+              "type": "string"
+            },
             "createAt": {
               "type": "string"
             },
+            "deleted": {
+              "type": "boolean",
+              "description": "Deleted marks a tombstone, a message deleted for everyone keeps its place without its text"
+            },
+            "deletedAt": {
+              "type": "string"
+            },
+            "deliveredTo": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Receipt"
+              }
+            },
+            "editHistory": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.MessageEdit"
+              }
+            },
+            "editedAt": {
+              "type": "string"
+            },
+            "expiresAt": {
+              "type": "string",
+              "description": "ExpiresAt is set when the chat had disappearing messages on when it was sent"
+            },
             "from": {
               "type": "string"
             },
             "id": {
               "type": "string"
             },
+            "mentions": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Mention"
+              }
+            },
+            "message": {
+              "type": "string"
+            },
+            "reactions": {
+              "type": "object",
+              "additionalProperties": {
+                "type": "array",
+                "items": {
+                  "type": "string"
+                }
+              },
+              "description": "Reactions maps each emoji to the users who reacted with it"
+            },
+            "readBy": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Receipt"
+              }
+            },
+            "replyCount": {
+              "type": "integer",
+              "description": "ReplyCount is the number of replies in the thread of a root message"
+            },
+            "replyTo": {
+              "type": "string",
+              "description": "ReplyTo is the message this one replies to or quotes, ThreadID is the root of its thread"
+            },
+            "threadId": {
+              "type": "string"
+            }
+          }
+        },
+        "models.MessageEdit": {
+          "type": "object",
+          "properties": {
+            "editedAt": {
+              "type": "string"
+            },
+            "message": {
+              "type": "string"
+            }
+          }
+        },
+        "models.MessagePage": {
+          "type": "object",
+          "properties": {
+            "hasMore": {
+              "type": "boolean"
+            },
+            "messages": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Message"
+              }
+            }
+          }
+        },
+        "models.MessageReceipts": {
+          "type": "object",
+          "properties": {
+            "deliveredTo": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Receipt"
+              }
+            },
+            "from": {
+              "type": "string"
+            },
+            "messageId": {
+              "type": "string"
+            },
+            "readBy": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Receipt"
+              }
+            }
+          }
+        },
+        "models.MessageSearchHit": {
+          "type": "object",
+          "properties": {
+            "message": {
+              "$ref": "#/components/schemas/models.Message"
+            },
+            "snippet": {
+              "type": "string"
+            }
+          }
+        },
+        "models.Pin": {
+          "type": "object",
+          "properties": {
+            "messageId": {
+              "type": "string"
+            },
+            "pinnedAt": {
+              "type": "string"
+            },
+            "pinnedBy": {
+              "type": "string"
+            }
+          }
+        },
+        "models.PinnedMessage": {
+          "type": "object",
+          "properties": {
             "message": {
+              "$ref": "#/components/schemas/models.Message"
+            },
+            "messageId": {
+              "type": "string"
+            },
+            "pinnedAt": {
+              "type": "string"
+            },
+            "pinnedBy": {
               "type": "string"
             }
           }
@@ -1632,6 +4925,103 @@ const docTemplate = `{
             }
           }
         },
+        "models.Receipt": {
+          "type": "object",
+          "properties": {
+            "at": {
+              "type": "string"
+            },
+            "userId": {
+              "type": "string"
+            }
+          }
+        },
+        "models.ScheduledMessage": {
+          "type": "object",
+          "properties": {
+            "attachments": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              },
+              "description": "Attachments are uploaded attachments, they are claimed by the message when it is sent"
+            },
+            "attempts": {
+              "type": "integer",
+              "description": "Attempts counts the failed attempts to send, Error is the last failure"
+            },
+            "chatId": {
+              "type": "string"
+            },
+            "createdAt": {
+              "type": "string"
+            },
+            "error": {
+              "type": "string"
+            },
+            "from": {
+              "type": "string"
+            },
+            "id": {
+              "type": "string"
+            },
+            "message": {
+              "type": "string"
+            },
+            "replyTo": {
+              "type": "string"
+            },
+            "sendAt": {
+              "type": "string"
+            },
+            "status": {
+              "type": "string"
+            },
+            "updatedAt": {
+              "type": "string"
+            }
+          }
+        },
+        "models.SearchResult": {
+          "type": "object",
+          "properties": {
+            "chats": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.ChatSearchHit"
+              }
+            },
+            "hasMore": {
+              "type": "boolean"
+            },
+            "messages": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.MessageSearchHit"
+              }
+            },
+            "nextCursor": {
+              "type": "string"
+            }
+          }
+        },
+        "models.ThreadPage": {
+          "type": "object",
+          "properties": {
+            "hasMore": {
+              "type": "boolean"
+            },
+            "messages": {
+              "type": "array",
+              "items": {
+                "$ref": "#/components/schemas/models.Message"
+              }
+            },
+            "root": {
+              "$ref": "#/components/schemas/models.Message"
+            }
+          }
+        },
         "models.User": {
           "type": "object",
           "properties": {
@@ -1,18 +1,32 @@
 package main
 
 import (
+	"cmp"
 	"log"
 
 	_ "github.com/Meeyok-Chat/backend/cmd/docs"
 	"github.com/Meeyok-Chat/backend/configs"
 	"github.com/Meeyok-Chat/backend/middleware"
+	"github.com/Meeyok-Chat/backend/repository/broadcast"
+	"github.com/Meeyok-Chat/backend/repository/cache"
 	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/repository/queue"
 	"github.com/Meeyok-Chat/backend/repository/queue/queuePublisher"
 	"github.com/Meeyok-Chat/backend/repository/queue/queueReceiver"
+	"github.com/Meeyok-Chat/backend/repository/queue/queueWorker"
+	"github.com/Meeyok-Chat/backend/repository/replay"
+	searchRepository "github.com/Meeyok-Chat/backend/repository/search"
+	"github.com/Meeyok-Chat/backend/repository/storage"
+	"github.com/Meeyok-Chat/backend/repository/ticket"
 	"github.com/Meeyok-Chat/backend/routes"
+	"github.com/Meeyok-Chat/backend/services/assistant"
+	"github.com/Meeyok-Chat/backend/services/attachment"
 	"github.com/Meeyok-Chat/backend/services/chat"
 	"github.com/Meeyok-Chat/backend/services/friendship"
 	"github.com/Meeyok-Chat/backend/services/post"
+	"github.com/Meeyok-Chat/backend/services/retention"
+	"github.com/Meeyok-Chat/backend/services/scheduler"
+	"github.com/Meeyok-Chat/backend/services/search"
 	"github.com/Meeyok-Chat/backend/services/user"
 	Websocket "github.com/Meeyok-Chat/backend/services/websocket"
 	"github.com/gin-gonic/gin"
@@ -54,27 +68,147 @@ func main() {
 		log.Fatalf("Error initializing Firebase auth: %v", err)
 	}
 
+	// Redis is optional, without it everything falls back to in-process implementations
+	var redisClient *configs.RedisClient
+	if configs.GetEnv("REDIS_URI") != "" {
+		redisClient, err = configs.NewRedisClient()
+		if err != nil {
+			log.Fatalf("Could not create Redis client: %v", err)
+		}
+	}
+
 	// Initialize a new repositories
 	chatRepo := database.NewChatRepo(mongoClient.Chat, mongoClient.User, mongoClient.Friendship)
+	messageRepo := database.NewMessageRepo(mongoClient.Message)
+	attachmentRepo := database.NewAttachmentRepo(mongoClient.Attachment)
+	readMarkerRepo := database.NewReadMarkerRepo(mongoClient.ReadMarker)
+	scheduledRepo := database.NewScheduledMessageRepo(mongoClient.Scheduled)
+	aiRequestRepo := database.NewAIRequestRepo(mongoClient.AIRequest)
+	deadLetterRepo := database.NewDeadLetterRepo(mongoClient.DeadLetter)
 	userRepo := database.NewUserRepo(mongoClient.User)
 	friendshipRepo := database.NewFriendshipRepo(mongoClient.Friendship)
 	postRepo := database.NewPostRepo(mongoClient.Post)
+	searchRepo := searchRepository.NewMongoSearchRepo(mongoClient.Message, mongoClient.Chat)
+
+	if err := chatRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create chat indexes: %v", err)
+	}
+	if err := chatRepo.BackfillOwners(); err != nil {
+		log.Fatalf("Could not store the owners of group chats: %v", err)
+	}
+	if err := messageRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create message indexes: %v", err)
+	}
+	if err := readMarkerRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create read marker indexes: %v", err)
+	}
+	if err := scheduledRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create scheduled message indexes: %v", err)
+	}
+	if err := aiRequestRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create AI request indexes: %v", err)
+	}
+	if err := searchRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create search indexes: %v", err)
+	}
+
+	// Initialize a blob store for attachments, files stay on the local disk unless a bucket is configured
+	var storageRepo storage.StorageRepo
+	if configs.GetEnv("STORAGE_S3_BUCKET") != "" {
+		storageRepo, err = storage.NewS3StorageRepo()
+		if err != nil {
+			log.Fatalf("Could not create S3 storage: %v", err)
+		}
+	} else {
+		storageRepo = storage.NewLocalStorageRepo(cmp.Or(configs.GetEnv("STORAGE_LOCAL_PATH"), "uploads"))
+	}
+
+	// Initialize a cache of the unread counters, shared by every instance when Redis is available
+	var unreadCacheRepo cache.UnreadCacheRepo
+	if redisClient != nil {
+		unreadCacheRepo = cache.NewRedisUnreadCacheRepo(redisClient)
+	} else {
+		unreadCacheRepo = cache.NewLocalUnreadCacheRepo()
+	}
 
 	// Initialize a new services
-	chatService := chat.NewChatService(chatRepo)
+	chatService := chat.NewChatService(chatRepo, messageRepo, userRepo, readMarkerRepo, unreadCacheRepo)
+	attachmentService := attachment.NewAttachmentService(attachmentRepo, storageRepo)
 	userService := user.NewUserService(userRepo)
 	friendshipService := friendship.NewFriendshipService(friendshipRepo, userRepo)
 	postService := post.NewPostService(postRepo, userRepo)
+	searchService := search.NewSearchService(searchRepo, chatRepo, chatService)
+
+	// Initialize a queue transport to the AI worker, it has to be chosen unless only SQS is configured
+	var queueRepo queue.QueueRepo
+	transport := cmp.Or(configs.GetEnv("QUEUE_TRANSPORT"), defaultQueueTransport())
+	switch transport {
+	case "sqs":
+		queueRepo, err = queue.NewSQSQueueRepo()
+		if err != nil {
+			log.Fatalf("Could not create SQS queue: %v", err)
+		}
+	case "redis":
+		if redisClient == nil {
+			log.Fatalf("QUEUE_TRANSPORT=redis needs REDIS_URI")
+		}
+		queueRepo = queue.NewRedisQueueRepo(redisClient)
+	case "local":
+		queueRepo = queue.NewLocalQueueRepo()
+		// Nothing outside the process can reach these queues, a stub answers in place of the AI worker
+		log.Println("QUEUE_TRANSPORT=local, a stub answers in place of Meeyok AI")
+		go queueWorker.NewStubWorker(queueRepo).Run()
+	case "":
+		log.Fatalf("QUEUE_TRANSPORT is not set, use sqs, redis or local")
+	default:
+		log.Fatalf("Unknown QUEUE_TRANSPORT %q, use sqs, redis or local", transport)
+	}
 
 	// Initialize a queue Publisher
-	queuePublisher := queuePublisher.NewQueuePublisher()
+	queuePublisher := queuePublisher.NewQueuePublisher(queueRepo)
+
+	// Initialize a broadcast bus, Redis is required once more than one instance is running
+	// a replay buffer so reconnecting clients can resume on any instance,
+	// the store of the tickets used to open a websocket
+	// and a buffer of the streamed replies of the AI
+	var broadcastRepo broadcast.BroadcastRepo
+	var replayRepo replay.ReplayRepo
+	var ticketRepo ticket.TicketRepo
+	var replyCacheRepo cache.ReplyCacheRepo
+	if redisClient != nil {
+		broadcastRepo = broadcast.NewRedisBroadcastRepo(redisClient)
+		replayRepo = replay.NewRedisReplayRepo(redisClient)
+		ticketRepo = ticket.NewRedisTicketRepo(redisClient)
+		replyCacheRepo = cache.NewRedisReplyCacheRepo(redisClient)
+	} else {
+		broadcastRepo = broadcast.NewLocalBroadcastRepo()
+		replayRepo = replay.NewLocalReplayRepo()
+		ticketRepo = ticket.NewLocalTicketRepo()
+		replyCacheRepo = cache.NewLocalReplyCacheRepo()
+	}
 
 	// Initialize a websocket manager
-	websocketManager := Websocket.NewManagerService(queuePublisher, chatRepo, userRepo)
+	websocketManager := Websocket.NewManagerService(queuePublisher, broadcastRepo, replayRepo, ticketRepo, replyCacheRepo, chatService, attachmentService, chatRepo, messageRepo, userRepo, aiRequestRepo)
 
-	// Initialize a queue manager Receiver
-	queueReceiver := queueReceiver.NewConsumerManager(websocketManager)
+	// Initialize a queue manager Receiver, replies that cannot be delivered end up in the dead-letter queue
+	queueReceiver := queueReceiver.NewConsumerManager(queueRepo, aiRequestRepo, deadLetterRepo, websocketManager)
 	go queueReceiver.ReadResult()
+	// Without a dead-letter queue on SQS the receiver stores dead letters itself
+	if transport != "sqs" || configs.GetEnv("AWS_SQS_DEADLETTERQUEUE_URL") != "" {
+		go queueReceiver.ReadDLQ()
+	}
+
+	// Initialize a monitor of the requests to the AI worker
+	assistantService := assistant.NewAssistantService(aiRequestRepo, deadLetterRepo, queueRepo, queuePublisher, websocketManager)
+	go assistantService.Monitor()
+
+	// Initialize a dispatcher of the scheduled messages
+	schedulerService := scheduler.NewSchedulerService(scheduledRepo, chatService, websocketManager)
+	go schedulerService.Dispatch()
+
+	// Initialize a sweeper of the disappearing messages
+	retentionService := retention.NewRetentionService(chatService, attachmentService, websocketManager)
+	go retentionService.Sweep()
 
 	// Initialize a new client for firebase authentication
 	middleware := middleware.NewAuthMiddleware(userService)
@@ -84,11 +218,23 @@ func main() {
 	r.Use(configs.EnableCORS())
 	routes.WebsocketRoute(r, middleware, FirebaseClient, websocketManager, chatService)
 	routes.ChatRoute(r, middleware, FirebaseClient, userService, chatService, websocketManager)
+	routes.AttachmentRoute(r, middleware, FirebaseClient, attachmentService, chatService)
 	routes.UserRoute(r, middleware, FirebaseClient, userService)
 	routes.FriendshipRoute(r, middleware, FirebaseClient, friendshipService)
 	routes.PostRoute(r, middleware, FirebaseClient, postService)
+	routes.SearchRoute(r, middleware, FirebaseClient, searchService)
+	routes.ScheduledRoute(r, middleware, FirebaseClient, schedulerService)
+	routes.AdminRoute(r, middleware, FirebaseClient, assistantService)
 
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	log.Fatal(r.Run(":" + configs.GetEnv("PORT")))
 }
+
+// defaultQueueTransport keeps deployments that only configured SQS on it, there is no default otherwise
+func defaultQueueTransport() string {
+	if configs.GetEnv("AWS_SQS_PUBLISHER") != "" || configs.GetEnv("AWS_SQS_RECEIVER_URL") != "" {
+		return "sqs"
+	}
+	return ""
+}
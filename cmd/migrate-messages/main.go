@@ -0,0 +1,101 @@
+// Command migrate-messages moves the messages embedded in chat documents into
+// the messages collection. It is safe to run again, messages that were already
+// moved are skipped.
+//
+//	go run ./cmd/migrate-messages
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+	mongoClient, err := configs.NewMongoClient()
+	if err != nil {
+		log.Fatalf("Could not create MongoDB client: %v", err)
+	}
+
+	messageRepo := database.NewMessageRepo(mongoClient.Message)
+	if err := messageRepo.CreateIndexes(); err != nil {
+		log.Fatalf("Could not create message indexes: %v", err)
+	}
+
+	ctx := context.Background()
+	cursor, err := mongoClient.Chat.Find(ctx, bson.M{"messages": bson.M{"$exists": true}})
+	if err != nil {
+		log.Fatalf("Could not read chats: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	chats, moved := 0, 0
+	for cursor.Next(ctx) {
+		var chat models.Chat
+		if err := cursor.Decode(&chat); err != nil {
+			log.Fatalf("Could not decode chat: %v", err)
+		}
+
+		n, err := migrateChat(mongoClient, chat)
+		if err != nil {
+			log.Fatalf("Could not migrate chat %s: %v", chat.ID.Hex(), err)
+		}
+		chats++
+		moved += n
+		log.Printf("Moved %d messages of chat %s", n, chat.ID.Hex())
+	}
+	if err := cursor.Err(); err != nil {
+		log.Fatalf("Could not read chats: %v", err)
+	}
+	log.Printf("Done, moved %d messages of %d chats", moved, chats)
+}
+
+func migrateChat(mongoClient *configs.MongoClient, chat models.Chat) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if len(chat.Messages) > 0 {
+		documents := []interface{}{}
+		for _, message := range chat.Messages {
+			message.ChatID = chat.ID
+			documents = append(documents, message)
+		}
+
+		// Unordered so a message moved by an earlier run doesn't stop the others
+		_, err := mongoClient.Message.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		if err != nil && !onlyDuplicateKeys(err) {
+			return 0, err
+		}
+	}
+
+	// Only drop the embedded messages once they are all in the messages collection
+	_, err := mongoClient.Chat.UpdateOne(ctx, bson.M{"_id": chat.ID}, bson.M{"$unset": bson.M{"messages": ""}})
+	if err != nil {
+		return 0, err
+	}
+	return len(chat.Messages), nil
+}
+
+const duplicateKeyCode = 11000
+
+// onlyDuplicateKeys reports whether every failed insert was a message that already exists
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return false
+		}
+	}
+	return true
+}
@@ -14,6 +14,12 @@ type MongoClient struct {
 	Client     *mongo.Client
 	User       *mongo.Collection
 	Chat       *mongo.Collection
+	Message    *mongo.Collection
+	Attachment *mongo.Collection
+	ReadMarker *mongo.Collection
+	Scheduled  *mongo.Collection
+	AIRequest  *mongo.Collection
+	DeadLetter *mongo.Collection
 	Friendship *mongo.Collection
 	Post       *mongo.Collection
 }
@@ -39,6 +45,12 @@ func NewMongoClient() (*MongoClient, error) {
 		Client:     mongoClient,
 		User:       mongoClient.Database("Golang").Collection("users"),
 		Chat:       mongoClient.Database("Golang").Collection("chats"),
+		Message:    mongoClient.Database("Golang").Collection("messages"),
+		Attachment: mongoClient.Database("Golang").Collection("attachments"),
+		ReadMarker: mongoClient.Database("Golang").Collection("readMarkers"),
+		Scheduled:  mongoClient.Database("Golang").Collection("scheduledMessages"),
+		AIRequest:  mongoClient.Database("Golang").Collection("aiRequests"),
+		DeadLetter: mongoClient.Database("Golang").Collection("deadLetters"),
 		Friendship: mongoClient.Database("Golang").Collection("friendships"),
 		Post:       mongoClient.Database("Golang").Collection("posts"),
 	}, nil
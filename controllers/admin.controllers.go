@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/services/assistant"
+	"github.com/gin-gonic/gin"
+)
+
+type adminController struct {
+	assistantService assistant.AssistantService
+}
+
+type AdminController interface {
+	GetAIRequests(c *gin.Context)
+	GetDeadLetters(c *gin.Context)
+	ReplayDeadLetter(c *gin.Context)
+}
+
+func NewAdminController(assistantService assistant.AssistantService) AdminController {
+	return &adminController{
+		assistantService: assistantService,
+	}
+}
+
+// GetAIRequests godoc
+// @Summary      List AI requests
+// @Description  Lists the requests made to Meeyok AI with their status and attempts, newest first
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false "Only requests in this status" Enums(pending, answered, failed, timed_out)
+// @Param        before  query     string  false "Only requests older than this correlation ID"
+// @Param        limit   query     int     false "Number of requests" default(20)
+// @Security     Bearer
+// @Success      200  {array}   models.AIRequest
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /admin/ai-requests [get]
+func (ac *adminController) GetAIRequests(c *gin.Context) {
+	limit, ok := adminLimit(c)
+	if !ok {
+		return
+	}
+
+	requests, err := ac.assistantService.GetAIRequests(c.Query("status"), c.Query("before"), limit)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetDeadLetters godoc
+// @Summary      List dead letters
+// @Description  Lists the queue messages that could not be handled, newest first
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        before   query     string  false "Only dead letters older than this dead letter ID"
+// @Param        limit    query     int     false "Number of dead letters" default(20)
+// @Param        pending  query     bool    false "Leave out the dead letters that were replayed"
+// @Security     Bearer
+// @Success      200  {object}  models.DeadLetterPage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /admin/dead-letters [get]
+func (ac *adminController) GetDeadLetters(c *gin.Context) {
+	limit, ok := adminLimit(c)
+	if !ok {
+		return
+	}
+	before := c.Query("before")
+	if !validCursor(before) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid dead letter cursor"})
+		return
+	}
+
+	page, err := ac.assistantService.GetDeadLetters(models.DeadLetterQuery{
+		Before:  before,
+		Limit:   limit,
+		Pending: c.Query("pending") == "true",
+	})
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// ReplayDeadLetter godoc
+// @Summary      Replay a dead letter
+// @Description  Sends a dead letter back into the queue it came from to be handled again, a dead letter is only replayed once
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Dead letter ID"
+// @Security     Bearer
+// @Success      200  {object}  models.DeadLetter
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      409  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /admin/dead-letters/{id}/replay [post]
+func (ac *adminController) ReplayDeadLetter(c *gin.Context) {
+	deadLetter, err := ac.assistantService.ReplayDeadLetter(c.Param("id"))
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, deadLetter)
+}
+
+// adminLimit reads the size of a page, it writes the error response and returns false when it is invalid
+func adminLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 100"})
+		return 0, false
+	}
+	return limit, true
+}
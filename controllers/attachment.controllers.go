@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/services/attachment"
+	"github.com/Meeyok-Chat/backend/services/chat"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left for the multipart framing around an uploaded file
+const multipartOverhead = 1 << 20
+
+type attachmentController struct {
+	attachmentService attachment.AttachmentService
+	chatService       chat.ChatService
+}
+
+type AttachmentController interface {
+	UploadAttachment(c *gin.Context)
+	GetAttachment(c *gin.Context)
+	GetThumbnail(c *gin.Context)
+}
+
+func NewAttachmentController(attachmentService attachment.AttachmentService, chatService chat.ChatService) AttachmentController {
+	return &attachmentController{
+		attachmentService: attachmentService,
+		chatService:       chatService,
+	}
+}
+
+// UploadAttachment godoc
+// @Summary      Upload an attachment
+// @Description  Uploads a file into the chat, send its id in the attachments of a send_message event to share it. Images get a thumbnail.
+// @Tags         attachments
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    path      string  true  "Chat ID"
+// @Param        file  formData  file    true  "File to upload, at most 10MB"
+// @Security     Bearer
+// @Success      200  {object}  models.Attachment
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      413  {object}  models.HTTPError
+// @Failure      415  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/attachments [post]
+func (ac *attachmentController) UploadAttachment(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, ac.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	// Cut an oversized upload off while it is read, instead of buffering it whole first
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxAttachmentSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": models.ErrAttachmentTooLarge.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "file is required"})
+		return
+	}
+	if fileHeader.Size > models.MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": models.ErrAttachmentTooLarge.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	// Read one byte more than allowed so an oversized file is still caught
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	attachment, err := ac.attachmentService.Upload(chatId, userID, fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(models.ErrorStatus(err, http.StatusBadRequest), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachment)
+}
+
+// GetAttachment godoc
+// @Summary      Download an attachment
+// @Description  Downloads the file of an attachment of the chat
+// @Tags         attachments
+// @Produce      octet-stream
+// @Param        id            path      string  true  "Chat ID"
+// @Param        attachmentId  path      string  true  "Attachment ID"
+// @Security     Bearer
+// @Success      200  {file}    file
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/attachments/{attachmentId} [get]
+func (ac *attachmentController) GetAttachment(c *gin.Context) {
+	ac.serveAttachment(c, false)
+}
+
+// GetThumbnail godoc
+// @Summary      Download the thumbnail of an attachment
+// @Description  Downloads the JPEG thumbnail of an image attachment of the chat
+// @Tags         attachments
+// @Produce      jpeg
+// @Param        id            path      string  true  "Chat ID"
+// @Param        attachmentId  path      string  true  "Attachment ID"
+// @Security     Bearer
+// @Success      200  {file}    file
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/attachments/{attachmentId}/thumbnail [get]
+func (ac *attachmentController) GetThumbnail(c *gin.Context) {
+	ac.serveAttachment(c, true)
+}
+
+// serveAttachment streams the file or the thumbnail of an attachment to a member of its chat
+func (ac *attachmentController) serveAttachment(c *gin.Context, thumbnail bool) {
+	chatId := c.Param("id")
+	if _, ok := authorizeChat(c, ac.chatService, chatId); !ok {
+		return
+	}
+
+	attachment, err := ac.attachmentService.GetAttachment(chatId, c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	reader, err := ac.attachmentService.Open(attachment, thumbnail)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	contentType, size := attachment.ContentType, attachment.Size
+	if thumbnail {
+		contentType, size = "image/jpeg", -1
+	}
+	headers := map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, reader, headers)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/Meeyok-Chat/backend/dtos"
@@ -24,10 +25,29 @@ type ChatController interface {
 	GetChatById(c *gin.Context)
 	GetUserChats(c *gin.Context)
 	CreateChat(c *gin.Context)
+	GetOrCreateDirectChat(c *gin.Context)
 	AddUsersToChat(c *gin.Context)
 	UpdateChat(c *gin.Context)
 	DeleteChat(c *gin.Context)
+	PromoteMember(c *gin.Context)
+	DemoteMember(c *gin.Context)
+	TransferOwnership(c *gin.Context)
+	LeaveChat(c *gin.Context)
+	RemoveMember(c *gin.Context)
 	GetMessages(c *gin.Context)
+	GetReceipts(c *gin.Context)
+	GetThread(c *gin.Context)
+	GetMentions(c *gin.Context)
+	EditMessage(c *gin.Context)
+	DeleteMessage(c *gin.Context)
+	AddReaction(c *gin.Context)
+	RemoveReaction(c *gin.Context)
+	GetPinnedMessages(c *gin.Context)
+	PinMessage(c *gin.Context)
+	UnpinMessage(c *gin.Context)
+	SetRetention(c *gin.Context)
+	SetAISettings(c *gin.Context)
+	GetOrCreateMeeyokChat(c *gin.Context)
 }
 
 func NewChatController(chatService chat.ChatService, userService user.UserService, websocketManager Websocket.ManagerService) ChatController {
@@ -40,12 +60,13 @@ func NewChatController(chatService chat.ChatService, userService user.UserServic
 
 // GetChats godoc
 // @Summary      List all chats
-// @Description  Retrieves a list of all available chats
+// @Description  Retrieves a list of all available chats, only for admins
 // @Tags         chats
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Success      200  {array}   models.Chat
+// @Failure      403  {object}  models.HTTPError
 // @Failure      500  {object}  models.HTTPError
 // @Router       /chats [get]
 func (cc *chatController) GetChats(c *gin.Context) {
@@ -65,30 +86,36 @@ func (cc *chatController) GetChats(c *gin.Context) {
 // @Produce      json
 // @Param        id           path      string  true  "Chat ID"
 // @Param        page         query     int     false "Page number for pagination" default(1)
-// @Param        num-message  query     int     false "Number of messages per page" default(10)
+// @Param        num-message  query     int     false "Number of messages per page, up to 100" default(10)
 // @Security     Bearer
 // @Success      200  {object}  models.Chat
 // @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
 // @Failure      500  {object}  models.HTTPError
 // @Router       /chats/{id} [get]
 func (cc *chatController) GetChatById(c *gin.Context) {
 	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
 
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request parameter"})
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "page must be 1 or more"})
 		return
 	}
 
 	numberOfMessages, err := strconv.Atoi(c.DefaultQuery("num-message", "10"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request parameter"})
+	if err != nil || numberOfMessages < 1 || numberOfMessages > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "num-message must be between 1 and 100"})
 		return
 	}
 
-	chat, err := cc.chatService.GetChatById(chatId, page, numberOfMessages)
+	chat, err := cc.chatService.GetChatById(chatId, userID, page, numberOfMessages)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, chat)
@@ -96,11 +123,11 @@ func (cc *chatController) GetChatById(c *gin.Context) {
 
 // GetUserChats godoc
 // @Summary      Get user chats based on type
-// @Description  Retrieves chats based on the given type (group, friend, non-friend)
+// @Description  Retrieves chats based on the given type (group, friend, non-friend, meeyok) with the unread counter and read marker of the caller
 // @Tags         chats
 // @Accept       json
 // @Produce      json
-// @Param        type   path     string  true  "Type of chat (group, friend, non-friend)"
+// @Param        type   path     string  true  "Type of chat (group, friend, non-friend, meeyok)"
 // @Security     Bearer
 // @Success      200  {array}   models.Chat
 // @Failure      400  {object}  models.HTTPError
@@ -151,7 +178,28 @@ func (cc *chatController) CreateChat(c *gin.Context) {
 		return
 	}
 
-	chat, err := cc.chatService.CreateChat(chatDTO)
+	// The creator is always a member of the chat
+	userID := c.GetString("id")
+	if !slices.Contains(chatDTO.Users, userID) {
+		chatDTO.Users = append(chatDTO.Users, userID)
+	}
+
+	// Individual chats are unique per pair of users, an existing one is returned instead
+	if chatDTO.Type == models.IndividualChatType {
+		users := slices.Compact(slices.Sorted(slices.Values(chatDTO.Users)))
+		if len(users) != 2 || len(chatDTO.Users) != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": models.ErrInvalidDirect.Error()})
+			return
+		}
+		otherUserID := users[0]
+		if otherUserID == userID {
+			otherUserID = users[1]
+		}
+		cc.getOrCreateDirectChat(c, userID, otherUserID)
+		return
+	}
+
+	chat, err := cc.chatService.CreateChat(chatDTO, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -169,9 +217,70 @@ func (cc *chatController) CreateChat(c *gin.Context) {
 	c.JSON(http.StatusOK, chat)
 }
 
+// GetOrCreateDirectChat godoc
+// @Summary      Get or create a direct chat
+// @Description  Returns the individual chat between the caller and the given user, creating it only when it does not exist yet
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        user  body      dtos.DirectChatRequest  true  "The other user of the chat"
+// @Security     Bearer
+// @Success      200   {object}  models.Chat
+// @Success      201   {object}  models.Chat
+// @Failure      400   {object}  models.HTTPError
+// @Failure      404   {object}  models.HTTPError
+// @Failure      500   {object}  models.HTTPError
+// @Router       /chats/direct [post]
+func (cc *chatController) GetOrCreateDirectChat(c *gin.Context) {
+	var req dtos.DirectChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	cc.getOrCreateDirectChat(c, c.GetString("id"), req.UserID)
+}
+
+// GetOrCreateMeeyokChat godoc
+// @Summary      Get or create the chat with Meeyok
+// @Description  Returns the chat of the caller with Meeyok AI, where every message is answered by the AI, creating it only when it does not exist yet
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Success      200   {object}  models.Chat
+// @Success      201   {object}  models.Chat
+// @Failure      404   {object}  models.HTTPError
+// @Failure      500   {object}  models.HTTPError
+// @Router       /chats/meeyok [post]
+func (cc *chatController) GetOrCreateMeeyokChat(c *gin.Context) {
+	chat, created, err := cc.chatService.GetOrCreateMeeyokChat(c.GetString("id"))
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, chat)
+		return
+	}
+	c.JSON(http.StatusOK, chat)
+}
+
+func (cc *chatController) getOrCreateDirectChat(c *gin.Context, userID string, otherUserID string) {
+	chat, created, err := cc.chatService.GetOrCreateDirectChat(userID, otherUserID)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, chat)
+		return
+	}
+	c.JSON(http.StatusOK, chat)
+}
+
 // AddUsersToChat godoc
 // @Summary      Add users to a chat
-// @Description  Adds specified users to an existing chat
+// @Description  Adds specified users to an existing group chat, only its owner and admins can add users
 // @Tags         chats
 // @Accept       json
 // @Produce      json
@@ -180,6 +289,8 @@ func (cc *chatController) CreateChat(c *gin.Context) {
 // @Security     Bearer
 // @Success      200   {object}  map[string]string
 // @Failure      400   {object}  models.HTTPError
+// @Failure      403   {object}  models.HTTPError
+// @Failure      404   {object}  models.HTTPError
 // @Failure      500   {object}  models.HTTPError
 // @Router       /chats/{id}/users [post]
 func (cc *chatController) AddUsersToChat(c *gin.Context) {
@@ -188,6 +299,9 @@ func (cc *chatController) AddUsersToChat(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "chatID is required"})
 		return
 	}
+	if _, ok := authorizeAction(c, cc.chatService, chatID, models.PermissionAddMembers); !ok {
+		return
+	}
 
 	var req dtos.AddUsersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -211,33 +325,38 @@ func (cc *chatController) AddUsersToChat(c *gin.Context) {
 
 // UpdateChat godoc
 // @Summary      Update a chat
-// @Description  Updates the details of an existing chat
+// @Description  Updates the details of an existing chat, only the owner and admins can rename a group chat
 // @Tags         chats
 // @Accept       json
 // @Produce      json
 // @Param        id    path      string  true  "Chat ID"
-// @Param        chat  body      models.Chat  true  "Updated chat details"
+// @Param        chat  body      dtos.UpdateChatRequest  true  "Updated chat details"
 // @Security     Bearer
 // @Success      200   {object}  map[string]string
 // @Failure      400   {object}  models.HTTPError
+// @Failure      403   {object}  models.HTTPError
+// @Failure      404   {object}  models.HTTPError
 // @Failure      500   {object}  models.HTTPError
 // @Router       /chats/{id} [put]
 func (cc *chatController) UpdateChat(c *gin.Context) {
-	chatId, exists := c.Get("chat")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Chat not found"})
+	chatId := c.Param("id")
+	if _, ok := authorizeAction(c, cc.chatService, chatId, models.PermissionRenameChat); !ok {
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(chatId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid chat ID"})
 		return
 	}
-	id := chatId.(primitive.ObjectID)
 
-	chatDTO := models.Chat{}
+	chatDTO := dtos.UpdateChatRequest{}
 	if err := c.ShouldBindJSON(&chatDTO); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
-	chatDTO.ID = id
-	if err := cc.chatService.UpdateChat(chatDTO); err != nil {
+	chat := models.Chat{ID: id, Name: chatDTO.Name}
+	if err := cc.chatService.UpdateChat(chat); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
@@ -246,49 +365,686 @@ func (cc *chatController) UpdateChat(c *gin.Context) {
 
 // DeleteChat godoc
 // @Summary      Delete a chat
-// @Description  Deletes a chat by its ID
+// @Description  Deletes a chat by its ID and tells its members with a chat_deleted event, only the owner can delete a group chat
 // @Tags         chats
 // @Accept       json
 // @Produce      json
 // @Param        id   path      string  true  "Chat ID"
 // @Security     Bearer
 // @Success      200  {object}  map[string]string
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
 // @Failure      500  {object}  models.HTTPError
 // @Router       /chats/{id} [delete]
 func (cc *chatController) DeleteChat(c *gin.Context) {
 	chatId := c.Param("id")
-	if err := cc.chatService.DeleteChat(chatId); err != nil {
+	if _, ok := authorizeAction(c, cc.chatService, chatId, models.PermissionDeleteChat); !ok {
+		return
+	}
+	members, err := cc.chatService.DeleteChat(chatId)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	cc.websocketManager.SendChatDeletedHandler(chatId, members, c.GetString("id"))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Chat deleted"})
 }
 
-func (cc *chatController) GetMessages(c *gin.Context) {
-	chatId, exists := c.Get("chat")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Chat not found"})
+// PromoteMember godoc
+// @Summary      Promote a member to admin
+// @Description  Makes a member of a group chat an admin, only the owner and admins can promote
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Chat ID"
+// @Param        userId  path      string  true  "User ID of the member"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/admins/{userId} [put]
+func (cc *chatController) PromoteMember(c *gin.Context) {
+	chatId := c.Param("id")
+	userID := c.Param("userId")
+	if err := cc.chatService.Promote(chatId, c.GetString("id"), userID); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendRoleChangedHandler(chatId, userID, models.RoleAdmin)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member promoted to admin"})
+}
+
+// DemoteMember godoc
+// @Summary      Demote an admin to member
+// @Description  Makes an admin of a group chat a member again, only the owner can demote other admins but an admin can always step down
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Chat ID"
+// @Param        userId  path      string  true  "User ID of the admin"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/admins/{userId} [delete]
+func (cc *chatController) DemoteMember(c *gin.Context) {
+	chatId := c.Param("id")
+	userID := c.Param("userId")
+	if err := cc.chatService.Demote(chatId, c.GetString("id"), userID); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendRoleChangedHandler(chatId, userID, models.RoleMember)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin demoted to member"})
+}
+
+// TransferOwnership godoc
+// @Summary      Transfer the ownership of a group chat
+// @Description  Hands a group chat over to another member, only the owner can do this and stays on as an admin
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                         true  "Chat ID"
+// @Param        owner  body      dtos.TransferOwnershipRequest  true  "New owner"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/owner [put]
+func (cc *chatController) TransferOwnership(c *gin.Context) {
+	chatId := c.Param("id")
+
+	var req dtos.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
-	id := chatId.(string)
 
-	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	ownerID := c.GetString("id")
+	if err := cc.chatService.TransferOwnership(chatId, ownerID, req.UserID); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if ownerID != req.UserID {
+		cc.websocketManager.SendRoleChangedHandler(chatId, req.UserID, models.RoleOwner)
+		cc.websocketManager.SendRoleChangedHandler(chatId, ownerID, models.RoleAdmin)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred"})
+}
+
+// LeaveChat godoc
+// @Summary      Leave a group chat
+// @Description  Takes the caller out of a group chat. An owner leaving hands the chat over to an admin, or to a member when there are no admins, and the last member leaving deletes the chat.
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Chat ID"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/leave [post]
+func (cc *chatController) LeaveChat(c *gin.Context) {
+	removal, err := cc.chatService.LeaveChat(c.Param("id"), c.GetString("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request parameter"})
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
+	cc.websocketManager.SendMemberRemovedHandler(removal)
 
-	numberOfMessages, err := strconv.Atoi(c.DefaultQuery("num-message", "10"))
+	c.JSON(http.StatusOK, gin.H{"message": "Left the chat"})
+}
+
+// RemoveMember godoc
+// @Summary      Remove a member from a group chat
+// @Description  Removes a member from a group chat, only the owner and admins can remove members and only the owner can remove admins
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Chat ID"
+// @Param        userId  path      string  true  "User ID of the member"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/users/{userId} [delete]
+func (cc *chatController) RemoveMember(c *gin.Context) {
+	removal, err := cc.chatService.RemoveMember(c.Param("id"), c.GetString("id"), c.Param("userId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request parameter"})
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
+	cc.websocketManager.SendMemberRemovedHandler(removal)
 
-	messages, err := cc.chatService.GetMessages(id, page, numberOfMessages)
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed from chat"})
+}
+
+// GetMessages godoc
+// @Summary      Get messages of a chat
+// @Description  Retrieves a page of messages of the chat in chronological order, use the id of the first message as the before cursor to load older messages
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Chat ID"
+// @Param        before  query     string  false "Only messages older than this message ID"
+// @Param        after   query     string  false "Only messages newer than this message ID"
+// @Param        limit   query     int     false "Number of messages" default(20)
+// @Security     Bearer
+// @Success      200  {object}  models.MessagePage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages [get]
+func (cc *chatController) GetMessages(c *gin.Context) {
+	id := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, id)
+	if !ok {
+		return
+	}
+
+	query, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+	query.Viewer = userID
+
+	messages, err := cc.chatService.GetMessages(id, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, messages)
 }
+
+// GetThread godoc
+// @Summary      Get a thread of a chat
+// @Description  Retrieves the root message of a thread with a page of its replies in chronological order
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true  "Chat ID"
+// @Param        messageId  path      string  true  "Root message ID"
+// @Param        before     query     string  false "Only replies older than this message ID"
+// @Param        after      query     string  false "Only replies newer than this message ID"
+// @Param        limit      query     int     false "Number of replies" default(20)
+// @Security     Bearer
+// @Success      200  {object}  models.ThreadPage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages/{messageId}/thread [get]
+func (cc *chatController) GetThread(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	query, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+	query.Viewer = userID
+
+	thread, err := cc.chatService.GetThread(chatId, c.Param("messageId"), query)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, thread)
+}
+
+// GetReceipts godoc
+// @Summary      Get message receipts of a chat
+// @Description  Retrieves the delivered and read receipts for a range of messages in the chat
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true  "Chat ID"
+// @Param        before  query     string  false "Only messages older than this message ID"
+// @Param        after   query     string  false "Only messages newer than this message ID"
+// @Param        limit   query     int     false "Number of messages" default(20)
+// @Security     Bearer
+// @Success      200  {array}   models.MessageReceipts
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/receipts [get]
+func (cc *chatController) GetReceipts(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	query, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+	query.Viewer = userID
+
+	receipts, err := cc.chatService.GetReceipts(chatId, query)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, receipts)
+}
+
+// GetMentions godoc
+// @Summary      Get unread mentions
+// @Description  Retrieves the messages mentioning the caller that the caller has not read yet, across all of their chats, newest first
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        before  query     string  false "Only messages older than this message ID"
+// @Param        limit   query     int     false "Number of messages" default(20)
+// @Security     Bearer
+// @Success      200  {object}  models.MessagePage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/mentions [get]
+func (cc *chatController) GetMentions(c *gin.Context) {
+	query, ok := messageQuery(c)
+	if !ok {
+		return
+	}
+	if query.After != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "mentions can only be paged with before"})
+		return
+	}
+
+	mentions, err := cc.chatService.GetUnreadMentions(c.GetString("id"), query)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, mentions)
+}
+
+// EditMessage godoc
+// @Summary      Edit a message
+// @Description  Changes the text of a message sent by the caller, the previous text is kept in its edit history
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string                   true  "Chat ID"
+// @Param        messageId  path      string                   true  "Message ID"
+// @Param        message    body      dtos.EditMessageRequest  true  "New text of the message"
+// @Security     Bearer
+// @Success      200  {object}  models.Message
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages/{messageId} [patch]
+func (cc *chatController) EditMessage(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	var req dtos.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	message, err := cc.chatService.EditMessage(chatId, c.Param("messageId"), userID, req.Message)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendMessageEditedHandler(message)
+
+	c.JSON(http.StatusOK, message)
+}
+
+// DeleteMessage godoc
+// @Summary      Delete a message
+// @Description  Deletes a message for the caller only, or for everyone when the caller sent it
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true   "Chat ID"
+// @Param        messageId  path      string  true   "Message ID"
+// @Param        scope      query     string  false  "me or everyone" default(me)
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages/{messageId} [delete]
+func (cc *chatController) DeleteMessage(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	scope := c.DefaultQuery("scope", "me")
+	if scope != "me" && scope != "everyone" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "scope must be me or everyone"})
+		return
+	}
+	forEveryone := scope == "everyone"
+
+	message, err := cc.chatService.DeleteMessage(chatId, c.Param("messageId"), userID, forEveryone)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendMessageDeletedHandler(message, userID, forEveryone)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted"})
+}
+
+// AddReaction godoc
+// @Summary      React to a message
+// @Description  Adds a reaction of the caller to a message
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string                true  "Chat ID"
+// @Param        messageId  path      string                true  "Message ID"
+// @Param        reaction   body      dtos.ReactionRequest  true  "Emoji to react with"
+// @Security     Bearer
+// @Success      200  {object}  models.Message
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages/{messageId}/reactions [post]
+func (cc *chatController) AddReaction(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	var req dtos.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	message, err := cc.chatService.AddReaction(chatId, c.Param("messageId"), userID, req.Emoji)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendReactionChangedHandler(message, userID, req.Emoji, true)
+
+	c.JSON(http.StatusOK, message)
+}
+
+// RemoveReaction godoc
+// @Summary      Remove a reaction from a message
+// @Description  Takes a reaction of the caller back
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true  "Chat ID"
+// @Param        messageId  path      string  true  "Message ID"
+// @Param        emoji      path      string  true  "Emoji of the reaction"
+// @Security     Bearer
+// @Success      200  {object}  models.Message
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/messages/{messageId}/reactions/{emoji} [delete]
+func (cc *chatController) RemoveReaction(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	emoji := c.Param("emoji")
+	message, err := cc.chatService.RemoveReaction(chatId, c.Param("messageId"), userID, emoji)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	cc.websocketManager.SendReactionChangedHandler(message, userID, emoji, false)
+
+	c.JSON(http.StatusOK, message)
+}
+
+// messageQuery reads the before/after cursors and the limit of a message page,
+// it writes the error response and returns false when they are invalid
+func messageQuery(c *gin.Context) (models.MessageQuery, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 100"})
+		return models.MessageQuery{}, false
+	}
+
+	query := models.MessageQuery{
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Limit:  limit,
+	}
+	if query.Before != "" && query.After != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "before and after cannot be used together"})
+		return models.MessageQuery{}, false
+	}
+	if !validCursor(query.Before) || !validCursor(query.After) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid message cursor"})
+		return models.MessageQuery{}, false
+	}
+	return query, true
+}
+
+func validCursor(cursor string) bool {
+	if cursor == "" {
+		return true
+	}
+	return primitive.IsValidObjectID(cursor)
+}
+
+// authorizeChat lets the request through only when the caller is a member of the chat,
+// otherwise it writes the error response and returns false
+func authorizeChat(c *gin.Context, chatService chat.ChatService, chatID string) (string, bool) {
+	userID := c.GetString("id")
+	if err := chatService.CheckMember(chatID, userID); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return "", false
+	}
+	return userID, true
+}
+
+// authorizeAction lets the request through only when the role of the caller in the chat allows the action,
+// otherwise it writes the error response and returns false
+func authorizeAction(c *gin.Context, chatService chat.ChatService, chatID string, permission models.Permission) (string, bool) {
+	userID := c.GetString("id")
+	if _, err := chatService.Authorize(chatID, userID, permission); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return "", false
+	}
+	return userID, true
+}
+
+// GetPinnedMessages godoc
+// @Summary      List the pinned messages of a chat
+// @Description  Retrieves the pinned messages of the chat, the latest pin first
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Chat ID"
+// @Security     Bearer
+// @Success      200  {array}   models.PinnedMessage
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/pins [get]
+func (cc *chatController) GetPinnedMessages(c *gin.Context) {
+	chatId := c.Param("id")
+	userID, ok := authorizeChat(c, cc.chatService, chatId)
+	if !ok {
+		return
+	}
+
+	pinned, err := cc.chatService.GetPinnedMessages(chatId, userID)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pinned)
+}
+
+// PinMessage godoc
+// @Summary      Pin a message
+// @Description  Pins a message of the chat, only the owner and admins of a group can pin. Pinning a pinned message does nothing.
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true  "Chat ID"
+// @Param        messageId  path      string  true  "Message ID"
+// @Security     Bearer
+// @Success      200  {object}  models.Pin
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      409  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/pins/{messageId} [put]
+func (cc *chatController) PinMessage(c *gin.Context) {
+	chatId := c.Param("id")
+	messageId := c.Param("messageId")
+	userID := c.GetString("id")
+
+	pin, pinned, err := cc.chatService.PinMessage(chatId, messageId, userID)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if pinned {
+		cc.websocketManager.SendPinnedChangedHandler(chatId, messageId, userID, true)
+	}
+	c.JSON(http.StatusOK, pin)
+}
+
+// UnpinMessage godoc
+// @Summary      Unpin a message
+// @Description  Unpins a message of the chat, only the owner and admins of a group can unpin
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true  "Chat ID"
+// @Param        messageId  path      string  true  "Message ID"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/pins/{messageId} [delete]
+func (cc *chatController) UnpinMessage(c *gin.Context) {
+	chatId := c.Param("id")
+	messageId := c.Param("messageId")
+	userID := c.GetString("id")
+
+	unpinned, err := cc.chatService.UnpinMessage(chatId, messageId, userID)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if unpinned {
+		cc.websocketManager.SendPinnedChangedHandler(chatId, messageId, userID, false)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Message unpinned"})
+}
+
+// SetRetention godoc
+// @Summary      Set disappearing messages
+// @Description  Sets how long new messages of the chat live before they disappear, only the owner and admins of a group can change it
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string                    true  "Chat ID"
+// @Param        retention  body      dtos.SetRetentionRequest  true  "Message lifetime"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/retention [put]
+func (cc *chatController) SetRetention(c *gin.Context) {
+	chatId := c.Param("id")
+	var req dtos.SetRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := c.GetString("id")
+	ttl := models.MessageTTLOptions[req.TTL]
+	changed, err := cc.chatService.SetMessageTTL(chatId, userID, ttl)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if changed {
+		cc.websocketManager.SendRetentionChangedHandler(chatId, userID, ttl)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Retention updated"})
+}
+
+// SetAISettings godoc
+// @Summary      Set up Meeyok AI in a chat
+// @Description  Turns Meeyok AI on or off in the chat and sets the persona it follows, only the owner and admins of a group can change them. Fields left out keep their value.
+// @Tags         chats
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                     true  "Chat ID"
+// @Param        settings  body      dtos.SetAISettingsRequest  true  "AI settings"
+// @Security     Bearer
+// @Success      200  {object}  models.Chat
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /chats/{id}/ai [put]
+func (cc *chatController) SetAISettings(c *gin.Context) {
+	var req dtos.SetAISettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := c.GetString("id")
+	chat, changed, toggled, err := cc.chatService.SetAISettings(c.Param("id"), userID, req)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	if changed {
+		cc.websocketManager.SendAISettingsChangedHandler(chat, userID, toggled)
+	}
+	c.JSON(http.StatusOK, chat)
+}
+
+func chatErrorStatus(err error) int {
+	return models.ErrorStatus(err, http.StatusInternalServerError)
+}
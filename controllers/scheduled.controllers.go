@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Meeyok-Chat/backend/dtos"
+	"github.com/Meeyok-Chat/backend/services/scheduler"
+	"github.com/gin-gonic/gin"
+)
+
+type scheduledController struct {
+	schedulerService scheduler.SchedulerService
+}
+
+type ScheduledController interface {
+	GetScheduledMessages(c *gin.Context)
+	ScheduleMessage(c *gin.Context)
+	EditScheduledMessage(c *gin.Context)
+	CancelScheduledMessage(c *gin.Context)
+}
+
+func NewScheduledController(schedulerService scheduler.SchedulerService) ScheduledController {
+	return &scheduledController{
+		schedulerService: schedulerService,
+	}
+}
+
+// GetScheduledMessages godoc
+// @Summary      List scheduled messages
+// @Description  Lists the messages the caller scheduled that were not sent yet in the order they are due, failed ones included with their error
+// @Tags         scheduled
+// @Accept       json
+// @Produce      json
+// @Param        chat  query     string  false  "Only messages scheduled in this chat"
+// @Security     Bearer
+// @Success      200  {array}   models.ScheduledMessage
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /scheduled [get]
+func (sc *scheduledController) GetScheduledMessages(c *gin.Context) {
+	messages, err := sc.schedulerService.GetScheduledMessages(c.GetString("id"), c.Query("chat"))
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// ScheduleMessage godoc
+// @Summary      Schedule a message
+// @Description  Stores a message to be sent into the chat at sendAt, as if the caller sent it then
+// @Tags         scheduled
+// @Accept       json
+// @Produce      json
+// @Param        message  body      dtos.ScheduleMessageRequest  true  "Message to schedule"
+// @Security     Bearer
+// @Success      201  {object}  models.ScheduledMessage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /scheduled [post]
+func (sc *scheduledController) ScheduleMessage(c *gin.Context) {
+	var req dtos.ScheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	message, err := sc.schedulerService.ScheduleMessage(c.GetString("id"), req)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, message)
+}
+
+// EditScheduledMessage godoc
+// @Summary      Edit a scheduled message
+// @Description  Changes the text or the time of a message that was not sent yet
+// @Tags         scheduled
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                            true  "Scheduled message ID"
+// @Param        message  body      dtos.EditScheduledMessageRequest  true  "Fields to change"
+// @Security     Bearer
+// @Success      200  {object}  models.ScheduledMessage
+// @Failure      400  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /scheduled/{id} [patch]
+func (sc *scheduledController) EditScheduledMessage(c *gin.Context) {
+	var req dtos.EditScheduledMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	message, err := sc.schedulerService.EditScheduledMessage(c.Param("id"), c.GetString("id"), req)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
+
+// CancelScheduledMessage godoc
+// @Summary      Cancel a scheduled message
+// @Description  Cancels a message that was not sent yet, or dismisses one that failed to send
+// @Tags         scheduled
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Scheduled message ID"
+// @Security     Bearer
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /scheduled/{id} [delete]
+func (sc *scheduledController) CancelScheduledMessage(c *gin.Context) {
+	if err := sc.schedulerService.CancelScheduledMessage(c.Param("id"), c.GetString("id")); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled message cancelled"})
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/services/search"
+	"github.com/gin-gonic/gin"
+)
+
+type searchController struct {
+	searchService search.SearchService
+}
+
+type SearchController interface {
+	Search(c *gin.Context)
+}
+
+func NewSearchController(searchService search.SearchService) SearchController {
+	return &searchController{
+		searchService: searchService,
+	}
+}
+
+// Search godoc
+// @Summary      Search messages and chats
+// @Description  Searches the text of the messages and the names of the chats of the caller. Snippets are HTML escaped with the matches wrapped in <mark> tags, use nextCursor as the before cursor of the next page.
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Param        q       query     string  true   "Text to search for"
+// @Param        chat    query     string  false  "Only search this chat"
+// @Param        from    query     string  false  "Only messages sent by this user ID"
+// @Param        since   query     string  false  "Only messages sent at or after this RFC 3339 time"
+// @Param        until   query     string  false  "Only messages sent before this RFC 3339 time"
+// @Param        before  query     string  false  "Only messages older than this message ID"
+// @Param        limit   query     int     false  "Number of messages" default(20)
+// @Security     Bearer
+// @Success      200  {object}  models.SearchResult
+// @Failure      400  {object}  models.HTTPError
+// @Failure      403  {object}  models.HTTPError
+// @Failure      404  {object}  models.HTTPError
+// @Failure      500  {object}  models.HTTPError
+// @Router       /search [get]
+func (sc *searchController) Search(c *gin.Context) {
+	query := models.SearchQuery{
+		Text:   strings.TrimSpace(c.Query("q")),
+		From:   c.Query("from"),
+		Before: c.Query("before"),
+	}
+	if query.Text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "q is required"})
+		return
+	}
+	if !validCursor(query.Before) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid message cursor"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 50"})
+		return
+	}
+	query.Limit = limit
+
+	for param, bound := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": param + " must be an RFC 3339 time"})
+			return
+		}
+		*bound = &t
+	}
+
+	result, err := sc.searchService.Search(c.GetString("id"), c.Query("chat"), query)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -59,6 +59,7 @@ func (uc userController) GetUsers(c *gin.Context) {
 // @Security     Bearer
 // @Param        id   path      string  true  "User ID"
 // @Success      200  {object}  models.User
+// @Failure      404  {object}  models.HTTPError
 // @Failure      500  {object}  models.HTTPError
 // @Router       /users/{id} [get]
 func (uc userController) GetUserByID(c *gin.Context) {
@@ -66,7 +67,7 @@ func (uc userController) GetUserByID(c *gin.Context) {
 
 	result, err := uc.userService.GetUserByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(models.ErrorStatus(err, http.StatusInternalServerError), gin.H{"message": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, result)
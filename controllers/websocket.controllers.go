@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Meeyok-Chat/backend/configs"
@@ -42,7 +43,7 @@ func (ws *websocketController) checkOrigin(r *http.Request) bool {
 
 // InitWebsocket godoc
 // @Summary      Initialize WebSocket connection
-// @Description  Prepares for WebSocket connection by checking existing client
+// @Description  Prepares for WebSocket connection by checking existing client, and issues a one-time ticket to open it with
 // @Tags         websocket
 // @Accept       json
 // @Produce      json
@@ -64,23 +65,54 @@ func (ws *websocketController) InitWebsocket(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"result": "fail"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"result": "success"})
+
+	ticket, err := ws.websocketManagerService.IssueTicket(id)
+	if err != nil {
+		c.Error(fmt.Errorf("error issuing ticket: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"result": "fail"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"result": "success", "ticket": ticket})
 }
 
 // ServeWS godoc
 // @Summary      Establish WebSocket connection
-// @Description  Upgrades HTTP connection to WebSocket for real-time communication
+// @Description  Upgrades HTTP connection to WebSocket for real-time communication, the user comes from the ticket issued by /ws/init
 // @Tags         websocket
 // @Accept       json
 // @Produce      json
-// @Security     Bearer
-// @Param        userID  path      string  true  "User ID"
+// @Param        userID    path      string  true   "User ID"
+// @Param        ticket    query     string  true   "One-time ticket from /ws/init"
+// @Param        last_seq  query     int     false  "Last sequence the client has seen, the events after it are replayed before live ones"
 // @Success      101     "Switching Protocols"
 // @Failure      400     {object}  models.HTTPError  "Bad Request"
-// @Failure      500     {object}  models.HTTPError  "Internal Server Error"
+// @Failure      401     {object}  models.HTTPError  "Unauthorized"
+// @Failure      403     {object}  models.HTTPError  "Forbidden"
 // @Router       /ws/{userID} [get]
 func (ws *websocketController) ServeWS(c *gin.Context) {
-	userID := c.Param("userID")
+	// Browsers cannot set headers on a websocket upgrade, so the identity is
+	// carried by a one-time ticket instead of the bearer token
+	userID, err := ws.websocketManagerService.RedeemTicket(c.Query("ticket"))
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid or expired ticket"})
+		return
+	}
+	if c.Param("userID") != userID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Ticket was issued to another user"})
+		return
+	}
+
+	// A reconnecting client resumes from the last sequence it has seen
+	var lastSeq *uint64
+	if value := c.Query("last_seq"); value != "" {
+		seq, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "last_seq must be a sequence number"})
+			return
+		}
+		lastSeq = &seq
+	}
 
 	// Begin by upgrading the HTTP request
 	websocketUpgrader := websocket.Upgrader{
@@ -96,17 +128,18 @@ func (ws *websocketController) ServeWS(c *gin.Context) {
 	}
 
 	// Add the newly created client to the manager
-	ws.websocketManagerService.AddClient(conn, c, userID)
+	ws.websocketManagerService.AddClient(conn, c, userID, lastSeq)
 }
 
 // GetClients godoc
 // @Summary      List active WebSocket clients
-// @Description  Retrieves a list of currently connected WebSocket clients
+// @Description  Retrieves a list of currently connected WebSocket clients, only for admins
 // @Tags         websocket
 // @Accept       json
 // @Produce      json
 // @Security     Bearer
 // @Success      200  {object}  map[string][]string
+// @Failure      403  {object}  models.HTTPError  "Forbidden"
 // @Failure      500  {object}  models.HTTPError  "Internal Server Error"
 // @Router       /ws/clients [get]
 func (ws *websocketController) GetClients(c *gin.Context) {
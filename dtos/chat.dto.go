@@ -6,6 +6,36 @@ type CreateChatRequest struct {
 	Type  string   `json:"type" binding:"required,oneof=Individual Group" example:"Group"`
 }
 
+type DirectChatRequest struct {
+	UserID string `json:"userId" binding:"required" example:"user456"`
+}
+
 type AddUsersRequest struct {
 	Users []string `json:"users" binding:"required" example:"user123,user456"`
 }
+
+type UpdateChatRequest struct {
+	Name string `json:"name" binding:"required" example:"Team Discussion"`
+}
+
+type SetRetentionRequest struct {
+	TTL string `json:"ttl" binding:"required,oneof=off 24h 7d" example:"24h"`
+}
+
+// SetAISettingsRequest changes the fields that are set, an empty persona clears it
+type SetAISettingsRequest struct {
+	Enabled *bool   `json:"enabled" example:"true"`
+	Persona *string `json:"persona" binding:"omitempty,max=2000" example:"You are a cheerful assistant who answers in Thai"`
+}
+
+type EditMessageRequest struct {
+	Message string `json:"message" binding:"required" example:"Hello, world"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required" example:"👍"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"userId" binding:"required" example:"user456"`
+}
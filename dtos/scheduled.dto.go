@@ -0,0 +1,17 @@
+package dtos
+
+import "time"
+
+type ScheduleMessageRequest struct {
+	ChatID      string    `json:"chatId" binding:"required" example:"64b7f0c2e4b0a1a2b3c4d5e6"`
+	Message     string    `json:"message" example:"Happy birthday!"`
+	ReplyTo     string    `json:"replyTo,omitempty" example:"64b7f0c2e4b0a1a2b3c4d5e7"`
+	Attachments []string  `json:"attachments,omitempty" example:"64b7f0c2e4b0a1a2b3c4d5e8"`
+	SendAt      time.Time `json:"sendAt" binding:"required" example:"2025-01-01T09:00:00Z"`
+}
+
+// EditScheduledMessageRequest changes only the fields that are set
+type EditScheduledMessageRequest struct {
+	Message *string    `json:"message,omitempty" example:"Happy birthday!!"`
+	SendAt  *time.Time `json:"sendAt,omitempty" example:"2025-01-01T10:00:00Z"`
+}
@@ -158,7 +158,8 @@ func (s authMiddleware) processToken(ctx *gin.Context, client *auth.Client, toke
 
 func (s authMiddleware) RoleAuth(allowedRoles ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		username, exists := ctx.Get("user")
+		// Auth identifies the user by email
+		username, exists := ctx.Get("email")
 		if !exists {
 			log.Println("User not found in context")
 			ctx.AbortWithStatus(http.StatusUnauthorized)
@@ -196,7 +197,7 @@ func (s authMiddleware) RoleAuth(allowedRoles ...string) gin.HandlerFunc {
 
 		log.Printf("User with email %s and role %s tried to access a route that requires roles: %v",
 			username, role, allowedRoles)
-		ctx.AbortWithStatus(http.StatusUnauthorized)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Your role does not allow this"})
 	}
 }
 
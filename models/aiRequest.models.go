@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AIRequest tracks a request to the AI worker from the moment it is published
+// until it is answered, it is keyed by the correlation ID of the request
+type AIRequest struct {
+	ID               string             `json:"id" bson:"_id"`
+	ChatID           primitive.ObjectID `json:"chatId" bson:"chatId"`
+	TriggerMessageID primitive.ObjectID `json:"triggerMessageId" bson:"triggerMessageId"`
+	RequestedBy      string             `json:"requestedBy" bson:"requestedBy"`
+	Status           string             `json:"status" bson:"status"`
+	// Attempts counts the times the request was published, SentAt is the last one
+	Attempts int       `json:"attempts" bson:"attempts"`
+	SentAt   time.Time `json:"sentAt" bson:"sentAt"`
+	// Payload is the published request, kept to publish it again
+	Payload        string              `json:"-" bson:"payload"`
+	Error          string              `json:"error,omitempty" bson:"error,omitempty"`
+	ReplyMessageID *primitive.ObjectID `json:"replyMessageId,omitempty" bson:"replyMessageId,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+const (
+	AIRequestPending  = "pending"
+	AIRequestAnswered = "answered"
+	AIRequestFailed   = "failed"
+	AIRequestTimedOut = "timed_out"
+)
+
+// AttemptCorrelationID is the correlation ID an attempt at the request is published with, every attempt
+// has its own so the deltas of two attempts never mix. The first attempt uses the ID of the request.
+func AttemptCorrelationID(requestID string, attempt int) string {
+	if attempt <= 1 {
+		return requestID
+	}
+	return requestID + "." + strconv.Itoa(attempt)
+}
+
+// AIRequestID returns the ID of the request the correlation ID of an attempt belongs to
+func AIRequestID(correlationID string) string {
+	requestID, _, _ := strings.Cut(correlationID, ".")
+	return requestID
+}
+
+const (
+	// AIRequestTimeout is how long the worker has to answer a request before it is published again
+	AIRequestTimeout = time.Minute
+	// AIRequestMaxAttempts bounds the times a request is published before it times out for good,
+	// the chat hears about it after AIRequestTimeout times this
+	AIRequestMaxAttempts = 2
+	// AIReplyMaxAttempts bounds the attempts to deliver a reply before it is dead-lettered
+	AIReplyMaxAttempts = 3
+	// AIRequestRateLimit bounds the requests a user makes to Meeyok AI within AIRequestRateWindow
+	AIRequestRateLimit  = 10
+	AIRequestRateWindow = time.Minute
+)
+
+// AIFailureMessage is posted into the chat by Meeyok AI when a request could not be answered
+const AIFailureMessage = "Sorry, I couldn't come up with an answer this time. Please try asking again in a moment."
+
+// DeadLetter is a queue message that could not be handled, kept to be inspected and replayed
+type DeadLetter struct {
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+	// Queue is the queue the message is replayed into
+	Queue         string     `json:"queue" bson:"queue"`
+	Body          string     `json:"body" bson:"body"`
+	Error         string     `json:"error,omitempty" bson:"error,omitempty"`
+	CorrelationID string     `json:"correlationId,omitempty" bson:"correlationId,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	ReplayedAt    *time.Time `json:"replayedAt,omitempty" bson:"replayedAt,omitempty"`
+}
+
+// DeadLetterQuery selects a page of dead letters, newest first. Before is a dead letter ID.
+type DeadLetterQuery struct {
+	Before string
+	Limit  int
+	// Pending leaves out the dead letters that were replayed already
+	Pending bool
+}
+
+type DeadLetterPage struct {
+	DeadLetters []DeadLetter `json:"deadLetters"`
+	HasMore     bool         `json:"hasMore"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment is a file uploaded into a chat, its blobs live in the storage repository
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	ChatID      primitive.ObjectID `json:"chatId" bson:"chatId"`
+	UploadedBy  string             `json:"uploadedBy" bson:"uploadedBy"`
+	FileName    string             `json:"fileName" bson:"fileName"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Size        int64              `json:"size" bson:"size"`
+	// Width and Height are only known for images
+	Width        int  `json:"width,omitempty" bson:"width,omitempty"`
+	Height       int  `json:"height,omitempty" bson:"height,omitempty"`
+	HasThumbnail bool `json:"hasThumbnail" bson:"hasThumbnail"`
+	// MessageID is set once the attachment is sent, an attachment belongs to a single message
+	MessageID    *primitive.ObjectID `json:"messageId,omitempty" bson:"messageId,omitempty"`
+	Key          string              `json:"-" bson:"key"`
+	ThumbnailKey string              `json:"-" bson:"thumbnailKey,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+const (
+	// MaxAttachmentSize is the largest file that can be uploaded
+	MaxAttachmentSize = 10 << 20
+	// MaxMessageAttachments is the number of attachments a single message can carry
+	MaxMessageAttachments = 10
+)
@@ -0,0 +1,12 @@
+package models
+
+// BroadcastMessage is published on the broadcast bus so that every instance
+// can deliver the event to the recipients whose socket it holds.
+// Broadcast sends the event to every connected client, otherwise it only goes to the Recipients.
+// Sequences holds the sequence number assigned to the event for each recipient, broadcast events have none.
+type BroadcastMessage struct {
+	Broadcast  bool              `json:"broadcast,omitempty"`
+	Recipients []string          `json:"recipients,omitempty"`
+	Sequences  map[string]uint64 `json:"sequences,omitempty"`
+	Event      Event             `json:"event"`
+}
@@ -1,28 +1,257 @@
 package models
 
 import (
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Chat struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id"`
-	Name      string             `json:"name,omitempty" bson:"name"`
-	Messages  []Message          `json:"messages"`
-	Users     []string           `json:"users,omitempty" bson:"users"`
-	Type      string             `json:"type,omitempty" bson:"type"`
-	UpdatedAt time.Time          `json:"updatedAt"`
+	ID       primitive.ObjectID `json:"id,omitempty" bson:"_id"`
+	Name     string             `json:"name,omitempty" bson:"name"`
+	Messages []Message          `json:"messages" bson:"messages,omitempty"`
+	Users    []string           `json:"users,omitempty" bson:"users"`
+	// Owner and Admins manage a group chat, every other user is a member
+	Owner  string   `json:"owner,omitempty" bson:"owner,omitempty"`
+	Admins []string `json:"admins,omitempty" bson:"admins,omitempty"`
+	// PairKey identifies the two users of an individual chat, or the user of a chat with Meeyok,
+	// a unique index keeps one chat per pair
+	PairKey string `json:"-" bson:"pairKey,omitempty"`
+	// Pins are the pinned messages of the chat in the order they were pinned
+	Pins []Pin `json:"pins,omitempty" bson:"pins,omitempty"`
+	// MessageTTL is how many seconds new messages live before they disappear, zero keeps them
+	MessageTTL int64 `json:"messageTtl,omitempty" bson:"messageTtl,omitempty"`
+	// AIDisabled turns Meeyok AI off in the chat, it is on unless turned off
+	AIDisabled bool `json:"aiDisabled,omitempty" bson:"aiDisabled,omitempty"`
+	// AIPersona is the system prompt Meeyok AI follows when it replies in the chat
+	AIPersona string    `json:"aiPersona,omitempty" bson:"aiPersona,omitempty"`
+	Type      string    `json:"type,omitempty" bson:"type"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// UnreadCount and LastReadMessageID are filled in for the user listing the chats
+	UnreadCount       int    `json:"unreadCount" bson:"-"`
+	LastReadMessageID string `json:"lastReadMessageId,omitempty" bson:"-"`
+}
+
+// Role returns the role of a member of the chat, members of individual chats are all equal
+func (c Chat) Role(userID string) string {
+	switch {
+	case c.Type != GroupChatType:
+		return RoleMember
+	case c.Owner != "" && userID == c.Owner:
+		return RoleOwner
+	case slices.Contains(c.Admins, userID):
+		return RoleAdmin
+	default:
+		return RoleMember
+	}
+}
+
+// MessageExpiry is when a message sent into the chat at sentAt disappears, nil when it stays
+func (c Chat) MessageExpiry(sentAt time.Time) *time.Time {
+	if c.MessageTTL <= 0 {
+		return nil
+	}
+	expiresAt := sentAt.Add(time.Duration(c.MessageTTL) * time.Second)
+	return &expiresAt
+}
+
+// AIEnabled reports whether Meeyok AI replies in the chat, it cannot be turned off in a chat with Meeyok
+func (c Chat) AIEnabled() bool {
+	return c.Type == MeeyokChatType || !c.AIDisabled
 }
 
+// MessageTTLOptions are the retention timers a chat can choose from
+var MessageTTLOptions = map[string]time.Duration{
+	"off": 0,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// ExpiredMessages are the messages of a chat that disappeared together
+type ExpiredMessages struct {
+	ChatID      string
+	MessageIDs  []string
+	Attachments []Attachment
+	// Members are the users of the chat to tell about it
+	Members []string
+}
+
+// DirectPairKey is the PairKey of the individual chat of two users, whatever their order
+func DirectPairKey(userID string, otherUserID string) string {
+	if otherUserID < userID {
+		userID, otherUserID = otherUserID, userID
+	}
+	return userID + ":" + otherUserID
+}
+
+// MeeyokPairKey is the PairKey of the chat of the user with Meeyok AI
+func MeeyokPairKey(userID string) string {
+	return DirectPairKey(userID, MeeyokAI)
+}
+
+// MemberRemoval describes a member leaving a group chat or being removed from it
+type MemberRemoval struct {
+	ChatID string
+	UserID string
+	// RemovedBy is empty when the member left on their own
+	RemovedBy string
+	// NewOwner is set when the owner left and the chat was handed over
+	NewOwner string
+	// Members are the users still in the chat, none when the last member left and the chat was deleted
+	Members []string
+}
+
+// Pin is a message pinned to the top of a chat
+type Pin struct {
+	MessageID primitive.ObjectID `json:"messageId" bson:"messageId"`
+	PinnedBy  string             `json:"pinnedBy" bson:"pinnedBy"`
+	PinnedAt  time.Time          `json:"pinnedAt" bson:"pinnedAt"`
+}
+
+// PinnedMessage is a pin together with the message it points to
+type PinnedMessage struct {
+	Pin
+	Message Message `json:"message"`
+}
+
+// MaxPinnedMessages caps the pins of a chat, the oldest have to be unpinned to pin more
+const MaxPinnedMessages = 50
+
+// ReadMarker is the last message of the chat the user has read
+type ReadMarker struct {
+	ChatID    primitive.ObjectID `json:"chatId" bson:"chatId"`
+	UserID    string             `json:"userId" bson:"userId"`
+	MessageID primitive.ObjectID `json:"messageId" bson:"messageId"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// MaxUnreadCount caps the unread messages counted in a chat, clients show it as 999+
+const MaxUnreadCount = 999
+
 type Message struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id"`
+	ChatID    primitive.ObjectID `json:"chatId" bson:"chatId"`
 	From      string             `json:"from"`
 	Message   string             `json:"message"`
 	CreatedAt time.Time          `json:"createAt"`
+	// ReplyTo is the message this one replies to or quotes, ThreadID is the root of its thread
+	ReplyTo  *primitive.ObjectID `json:"replyTo,omitempty" bson:"replyTo,omitempty"`
+	ThreadID *primitive.ObjectID `json:"threadId,omitempty" bson:"threadId,omitempty"`
+	// ReplyCount is the number of replies in the thread of a root message
+	ReplyCount  int           `json:"replyCount,omitempty" bson:"replyCount,omitempty"`
+	DeliveredTo []Receipt     `json:"deliveredTo,omitempty" bson:"deliveredTo,omitempty"`
+	ReadBy      []Receipt     `json:"readBy,omitempty" bson:"readBy,omitempty"`
+	EditedAt    *time.Time    `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+	EditHistory []MessageEdit `json:"editHistory,omitempty" bson:"editHistory,omitempty"`
+	// Deleted marks a tombstone, a message deleted for everyone keeps its place without its text
+	Deleted   bool       `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	// DeletedFor lists the users who deleted the message only for themselves
+	DeletedFor []string `json:"-" bson:"deletedFor,omitempty"`
+	// Reactions maps each emoji to the users who reacted with it
+	Reactions   map[string][]string `json:"reactions,omitempty" bson:"reactions,omitempty"`
+	Attachments []Attachment        `json:"attachments,omitempty" bson:"attachments,omitempty"`
+	Mentions    []Mention           `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	// ExpiresAt is set when the chat had disappearing messages on when it was sent
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
+
+// Mention is an @username in the text of a message that resolved to a member of the chat.
+// Offset and Length count UTF-16 code units, the way JavaScript indexes strings.
+type Mention struct {
+	UserID   string `json:"userId" bson:"userId"`
+	Username string `json:"username" bson:"username"`
+	Offset   int    `json:"offset" bson:"offset"`
+	Length   int    `json:"length" bson:"length"`
+}
+
+// MaxMentions caps the distinct usernames resolved in a message, later ones stay plain text
+const MaxMentions = 20
+
+// MaxMessageRunes is the longest text a user can send or edit a message to
+const MaxMessageRunes = 4000
+
+// MessageEdit is a previous version of an edited message
+type MessageEdit struct {
+	Message  string    `json:"message" bson:"message"`
+	EditedAt time.Time `json:"editedAt" bson:"editedAt"`
+}
+
+// MessageQuery selects a page of messages of a chat, Before and After are message IDs.
+// Messages the Viewer deleted for themselves are left out, and ThreadID only keeps the replies of a thread.
+type MessageQuery struct {
+	Viewer   string
+	ThreadID string
+	Before   string
+	After    string
+	Skip     int
+	Limit    int
+}
+
+type MessagePage struct {
+	Messages []Message `json:"messages"`
+	HasMore  bool      `json:"hasMore"`
+}
+
+type ThreadPage struct {
+	Root     Message   `json:"root"`
+	Messages []Message `json:"messages"`
+	HasMore  bool      `json:"hasMore"`
+}
+
+type Receipt struct {
+	UserID string    `json:"userId" bson:"userId"`
+	At     time.Time `json:"at" bson:"at"`
+}
+
+// MessageReceipts is the receipt state of a single message
+type MessageReceipts struct {
+	MessageID   primitive.ObjectID `json:"messageId"`
+	From        string             `json:"from"`
+	DeliveredTo []Receipt          `json:"deliveredTo"`
+	ReadBy      []Receipt          `json:"readBy"`
 }
 
 const (
 	IndividualChatType = "Individual"
 	GroupChatType      = "Group"
+	// MeeyokChatType is the chat of a user with Meeyok AI, every message in it is sent to the AI
+	MeeyokChatType = "Meeyok"
 )
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Permission is an action on a chat that not every member may take
+type Permission string
+
+const (
+	PermissionRenameChat    Permission = "rename_chat"
+	PermissionAddMembers    Permission = "add_members"
+	PermissionRemoveMembers Permission = "remove_members"
+	PermissionPromote       Permission = "promote"
+	PermissionDemote        Permission = "demote"
+	PermissionTransferOwner Permission = "transfer_owner"
+	PermissionDeleteChat    Permission = "delete_chat"
+	PermissionPinMessages   Permission = "pin_messages"
+	PermissionSetRetention  Permission = "set_retention"
+	PermissionConfigureAI   Permission = "configure_ai"
+)
+
+// MeeyokAI is the sender of the messages written by the AI
+const MeeyokAI = "Meeyok AI"
+
+// SystemSender is the sender of the messages the server writes into a chat, like "Alice removed Bob"
+const SystemSender = "system"
+
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+// MaxReceiptMessages caps the messages a single receipt marks, the newest are marked first
+const MaxReceiptMessages = 100
@@ -1,9 +1,84 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var ErrChatNotInCache = errors.New("chat not in cache")
 
+var (
+	ErrChatNotFound     = errors.New("chat not found")
+	ErrNotChatMember    = errors.New("user is not a member of this chat")
+	ErrMessageNotFound  = errors.New("message not found")
+	ErrMessageExists    = errors.New("message already exists")
+	ErrNotMessageSender = errors.New("only the sender can change this message")
+	ErrInvalidEmoji     = errors.New("invalid emoji")
+	ErrNotThreadRoot    = errors.New("message is a reply, not the root of a thread")
+	ErrEmptyMessage     = errors.New("message has no text and no attachments")
+	ErrMessageTooLong   = errors.New("message is too long")
+	ErrTooManyPins      = errors.New("this chat has reached the limit of pinned messages")
+)
+
+var (
+	ErrPermissionDenied = errors.New("your role in this chat does not allow this")
+	ErrNotGroupChat     = errors.New("only group chats allow this")
+	ErrMemberNotFound   = errors.New("target user is not a member of this chat")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidDirect    = errors.New("an individual chat needs exactly two different users")
+)
+
+var (
+	ErrScheduledNotFound = errors.New("scheduled message not found")
+	ErrInvalidSchedule   = errors.New("a message can only be scheduled in the future, up to a year ahead")
+)
+
+var (
+	ErrAIRequestNotFound  = errors.New("AI request not found")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrDeadLetterReplayed = errors.New("dead letter was replayed already")
+	ErrAIRequired         = errors.New("Meeyok AI cannot be turned off in a chat with Meeyok")
+	ErrReplyIncomplete    = errors.New("not every part of the reply arrived yet")
+	ErrAIRateLimited      = errors.New("Meeyok AI was asked too often, try again in a minute")
+	ErrAIUnavailable      = errors.New("Meeyok AI could not take the request, try again later")
+)
+
+var (
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentTooLarge    = errors.New("attachment is too large")
+	ErrUnsupportedAttachment = errors.New("attachment type is not supported")
+	ErrTooManyAttachments    = errors.New("too many attachments")
+)
+
+// ErrorStatus maps the errors above to the HTTP status code the API answers with,
+// any other error gets the fallback code
+func ErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrNotChatMember), errors.Is(err, ErrNotMessageSender), errors.Is(err, ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, ErrChatNotFound), errors.Is(err, ErrMessageNotFound), errors.Is(err, ErrAttachmentNotFound),
+		errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrScheduledNotFound),
+		errors.Is(err, ErrAIRequestNotFound), errors.Is(err, ErrDeadLetterNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidEmoji), errors.Is(err, ErrNotThreadRoot), errors.Is(err, ErrEmptyMessage), errors.Is(err, ErrMessageTooLong),
+		errors.Is(err, ErrTooManyAttachments), errors.Is(err, ErrNotGroupChat),
+		errors.Is(err, ErrInvalidDirect), errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrAIRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTooManyPins), errors.Is(err, ErrMessageExists), errors.Is(err, ErrDeadLetterReplayed):
+		return http.StatusConflict
+	case errors.Is(err, ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrAIRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrAIUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrUnsupportedAttachment):
+		return http.StatusUnsupportedMediaType
+	default:
+		return fallback
+	}
+}
+
 type HTTPError struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
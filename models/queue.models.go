@@ -1,10 +1,91 @@
 package models
 
+import "time"
+
+// QueuePayloadVersion is the version of the QueuePublisherPayload schema,
+// workers check it before reading anything but From
+const QueuePayloadVersion = 2
+
+// QueuePublisherPayload asks the AI worker to reply in a chat, with the context it needs to do so.
+// From is the chat ID, it is all version 1 of the schema had.
 type QueuePublisherPayload struct {
-	From string `json:"from"`
+	Version int    `json:"version"`
+	From    string `json:"from"`
+	// CorrelationID identifies the request, the worker sends it back with every part of the reply
+	CorrelationID string `json:"correlationId"`
+	// Chat describes the chat and its members
+	Chat AIChatContext `json:"chat"`
+	// Messages are the messages before the trigger, oldest first
+	Messages []AIContextMessage `json:"messages"`
+	// Trigger is the message the AI was asked to reply to
+	Trigger AIContextMessage `json:"trigger"`
+	// Prompt is what the AI was asked, the text of the trigger without the mention of Meeyok AI
+	Prompt string `json:"prompt"`
+	// Truncated is set when older messages or long texts were left out to fit the budget
+	Truncated bool `json:"truncated"`
+}
+
+type AIChatContext struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name,omitempty"`
+	Type    string            `json:"type"`
+	Members []AIContextMember `json:"members"`
+	// Persona is the system prompt the chat set for Meeyok AI
+	Persona string `json:"persona,omitempty"`
+}
+
+type AIContextMember struct {
+	UserID      string `json:"userId"`
+	DisplayName string `json:"displayName"`
+	Role        string `json:"role"`
 }
 
+type AIContextMessage struct {
+	ID          string                `json:"id"`
+	From        string                `json:"from"`
+	DisplayName string                `json:"displayName"`
+	Role        string                `json:"role"`
+	Text        string                `json:"text"`
+	ReplyTo     string                `json:"replyTo,omitempty"`
+	Attachments []AIContextAttachment `json:"attachments,omitempty"`
+	CreatedAt   time.Time             `json:"createdAt"`
+}
+
+type AIContextAttachment struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+}
+
+// Roles of the senders of context messages that are not members of the chat
+const (
+	AIRoleAssistant = "assistant"
+	AIRoleSystem    = "system"
+)
+
+// Budget of the context sent to the AI worker. Tokens are estimated from the size of the texts.
+const (
+	AIContextMaxMessages = 50
+	AIContextMaxTokens   = 4000
+	// AIContextMaxMessageRunes cuts single long messages so one of them cannot take the whole budget
+	AIContextMaxMessageRunes = 2000
+)
+
+// QueueReceiverPayload is a reply of the AI worker in the chat From. A reply is either complete
+// at once, or streamed as deltas in Seq order followed by a done part. Message is the whole reply,
+// a done part without it is assembled from the deltas once all Deltas of them arrived.
 type QueueReceiverPayload struct {
-	From    string `json:"from"`
-	Message string `json:"message"`
+	From          string `json:"from"`
+	Message       string `json:"message"`
+	CorrelationID string `json:"correlationId,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Seq           int    `json:"seq,omitempty"`
+	Delta         string `json:"delta,omitempty"`
+	// Deltas is the number of deltas the reply was streamed in, it is set on the done part
+	Deltas int `json:"deltas,omitempty"`
 }
+
+// Types of the parts of a streamed reply
+const (
+	AIReplyDelta = "delta"
+	AIReplyDone  = "done"
+)
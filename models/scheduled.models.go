@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduledMessage is a message written now and sent into the chat at SendAt
+type ScheduledMessage struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	ChatID  primitive.ObjectID `json:"chatId" bson:"chatId"`
+	From    string             `json:"from" bson:"from"`
+	Message string             `json:"message" bson:"message"`
+	ReplyTo string             `json:"replyTo,omitempty" bson:"replyTo,omitempty"`
+	// Attachments are uploaded attachments, they are claimed by the message when it is sent
+	Attachments []primitive.ObjectID `json:"attachments,omitempty" bson:"attachments,omitempty"`
+	SendAt      time.Time            `json:"sendAt" bson:"sendAt"`
+	Status      string               `json:"status" bson:"status"`
+	// Attempts counts the failed attempts to send, Error is the last failure
+	Attempts int    `json:"attempts,omitempty" bson:"attempts,omitempty"`
+	Error    string `json:"error,omitempty" bson:"error,omitempty"`
+	// ClaimedAt is set while an instance is sending the message
+	ClaimedAt *time.Time `json:"-" bson:"claimedAt,omitempty"`
+	// MessageID is picked on the first claim and kept by every attempt, so the message is stored once
+	MessageID *primitive.ObjectID `json:"-" bson:"messageId,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+const (
+	ScheduledPending = "pending"
+	ScheduledSending = "sending"
+	ScheduledFailed  = "failed"
+)
+
+const (
+	// MaxScheduleAhead is how far in the future a message can be scheduled
+	MaxScheduleAhead = 365 * 24 * time.Hour
+	// MaxScheduledAttempts bounds the attempts to send a scheduled message
+	MaxScheduledAttempts = 3
+	// ScheduledRetryBackoff is the delay before the first retry of a message, it doubles on every retry
+	ScheduledRetryBackoff = 30 * time.Second
+)
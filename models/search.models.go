@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// SearchQuery searches the messages and chat names of ChatIDs for Text.
+// Before is the ID of the last message of the previous page, results are newest first.
+type SearchQuery struct {
+	Text    string
+	Viewer  string
+	ChatIDs []string
+	From    string
+	Since   *time.Time
+	Until   *time.Time
+	Before  string
+	Limit   int
+}
+
+// MessageSearchHit is a matching message, Snippet is an HTML escaped excerpt of
+// its text with the matched terms wrapped in <mark> tags
+type MessageSearchHit struct {
+	Message Message `json:"message"`
+	Snippet string  `json:"snippet"`
+}
+
+type ChatSearchHit struct {
+	Chat    Chat   `json:"chat"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchResult only has chat hits on the first page, NextCursor is the before cursor of the next page
+type SearchResult struct {
+	Chats      []ChatSearchHit    `json:"chats"`
+	Messages   []MessageSearchHit `json:"messages"`
+	HasMore    bool               `json:"hasMore"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
@@ -34,6 +34,43 @@ const (
 	EventLeaveUser = "leave_user"
 
 	EventNewGroup = "new_group"
+
+	EventError = "error"
+
+	EventResumed = "resumed"
+
+	EventMarkDelivered = "mark_delivered"
+	EventMarkRead      = "mark_read"
+	EventReceipt       = "receipt"
+
+	EventTypingStart = "typing_start"
+	EventTypingStop  = "typing_stop"
+
+	EventEditMessage    = "edit_message"
+	EventDeleteMessage  = "delete_message"
+	EventMessageEdited  = "message_edited"
+	EventMessageDeleted = "message_deleted"
+
+	EventAddReaction     = "add_reaction"
+	EventRemoveReaction  = "remove_reaction"
+	EventReactionChanged = "reaction_changed"
+
+	EventMention = "mention"
+
+	EventUnreadCount = "unread_count"
+
+	EventRoleChanged   = "role_changed"
+	EventMemberRemoved = "member_removed"
+	EventChatDeleted   = "chat_deleted"
+
+	EventPinnedChanged = "pinned_changed"
+
+	EventRetentionChanged = "retention_changed"
+	EventMessagesExpired  = "messages_expired"
+
+	EventAIReplyDelta      = "ai_reply_delta"
+	EventAIReplyDone       = "ai_reply_done"
+	EventAISettingsChanged = "ai_settings_changed"
 )
 
 type EventHandler func(event Event, c *Client) error
@@ -41,13 +78,27 @@ type EventHandler func(event Event, c *Client) error
 type Event struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
+	// Seq is a per-user, monotonically increasing number stamped on events sent to specific users
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 type SendMessageEvent struct {
+	ID        string    `json:"id,omitempty"`
 	ChatID    string    `json:"chat_id"`
 	Message   string    `json:"message"`
 	From      string    `json:"from"`
 	CreatedAt time.Time `json:"createAt"`
+	// ReplyTo is the message replied to, the server fills in the ThreadID it belongs to
+	ReplyTo  string `json:"reply_to,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+	// Attachments only need the IDs of uploaded attachments, the server fills in the rest
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Mentions are parsed from the message by the server
+	Mentions []Mention `json:"mentions,omitempty"`
+	// ExpiresAt is when the message disappears from a chat with disappearing messages
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// CorrelationID links a reply of Meeyok AI to the deltas it was streamed with
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 type NewUserStatusEvent struct {
@@ -57,3 +108,158 @@ type NewUserStatusEvent struct {
 type NewGroupEvent struct {
 	ChatID string `json:"chat_id"`
 }
+
+// ResumedEvent closes a resume handshake, when Complete is false some events
+// were no longer buffered and the client has to reload its chats over REST
+type ResumedEvent struct {
+	LastSeq  uint64 `json:"last_seq"`
+	Complete bool   `json:"complete"`
+}
+
+// MarkMessagesEvent marks the unread messages of the chat up to and including MessageID,
+// at most models.MaxReceiptMessages of the newest of them
+type MarkMessagesEvent struct {
+	ChatID    string `json:"chat_id"`
+	MessageID string `json:"message_id"`
+}
+
+type ReceiptEvent struct {
+	ChatID     string    `json:"chat_id"`
+	UserID     string    `json:"user_id"`
+	Status     string    `json:"status"`
+	MessageIDs []string  `json:"message_ids"`
+	At         time.Time `json:"at"`
+}
+
+// TypingEvent is sent by a client with only ChatID, and fanned out with the typing UserID
+type TypingEvent struct {
+	ChatID string `json:"chat_id"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+type EditMessageEvent struct {
+	ChatID    string `json:"chat_id"`
+	MessageID string `json:"message_id"`
+	Message   string `json:"message"`
+}
+
+type DeleteMessageEvent struct {
+	ChatID      string `json:"chat_id"`
+	MessageID   string `json:"message_id"`
+	ForEveryone bool   `json:"for_everyone"`
+}
+
+type MessageEditedEvent struct {
+	ChatID    string    `json:"chat_id"`
+	MessageID string    `json:"message_id"`
+	Message   string    `json:"message"`
+	Mentions  []Mention `json:"mentions,omitempty"`
+	EditedAt  time.Time `json:"edited_at"`
+}
+
+type MessageDeletedEvent struct {
+	ChatID      string    `json:"chat_id"`
+	MessageID   string    `json:"message_id"`
+	ForEveryone bool      `json:"for_everyone"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type ReactionEvent struct {
+	ChatID    string `json:"chat_id"`
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// ReactionChangedEvent carries the change and every reaction of the message after it
+type ReactionChangedEvent struct {
+	ChatID    string              `json:"chat_id"`
+	MessageID string              `json:"message_id"`
+	UserID    string              `json:"user_id"`
+	Emoji     string              `json:"emoji"`
+	Added     bool                `json:"added"`
+	Reactions map[string][]string `json:"reactions"`
+}
+
+// MentionEvent goes to every mentioned user, even when they muted the chat
+type MentionEvent struct {
+	ChatID    string    `json:"chat_id"`
+	MessageID string    `json:"message_id"`
+	From      string    `json:"from"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createAt"`
+}
+
+// UnreadCountEvent is the new unread counter of a chat of the user
+type UnreadCountEvent struct {
+	ChatID            string `json:"chat_id"`
+	UnreadCount       int    `json:"unread_count"`
+	LastReadMessageID string `json:"last_read_message_id,omitempty"`
+}
+
+// RoleChangedEvent tells the members of a group chat about the new role of a member
+type RoleChangedEvent struct {
+	ChatID string `json:"chat_id"`
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// MemberRemovedEvent goes to the members of the chat and to the removed user,
+// whose client drops the chat. RemovedBy is empty when the user left.
+type MemberRemovedEvent struct {
+	ChatID    string `json:"chat_id"`
+	UserID    string `json:"user_id"`
+	RemovedBy string `json:"removed_by,omitempty"`
+}
+
+// ChatDeletedEvent tells the members of a chat that it was deleted, clients drop the chat
+type ChatDeletedEvent struct {
+	ChatID    string `json:"chat_id"`
+	DeletedBy string `json:"deleted_by"`
+}
+
+// PinnedChangedEvent tells the members of a chat that a message was pinned or unpinned by a user
+type PinnedChangedEvent struct {
+	ChatID    string    `json:"chat_id"`
+	MessageID string    `json:"message_id"`
+	Pinned    bool      `json:"pinned"`
+	By        string    `json:"by"`
+	At        time.Time `json:"at"`
+}
+
+// RetentionChangedEvent tells the members of a chat the new lifetime of its messages, zero turns disappearing messages off
+type RetentionChangedEvent struct {
+	ChatID     string `json:"chat_id"`
+	MessageTTL int64  `json:"message_ttl"`
+	By         string `json:"by"`
+}
+
+// AISettingsChangedEvent tells the members of a chat whether Meeyok AI replies in it and the persona it follows
+type AISettingsChangedEvent struct {
+	ChatID    string `json:"chat_id"`
+	AIEnabled bool   `json:"ai_enabled"`
+	AIPersona string `json:"ai_persona"`
+	By        string `json:"by"`
+}
+
+// MessagesExpiredEvent tells the members of a chat which messages disappeared, clients purge them
+type MessagesExpiredEvent struct {
+	ChatID     string   `json:"chat_id"`
+	MessageIDs []string `json:"message_ids"`
+}
+
+// AIReplyDeltaEvent is a part of a reply of Meeyok AI that is still being written,
+// clients append the deltas of a correlation ID in Seq order
+type AIReplyDeltaEvent struct {
+	ChatID        string `json:"chat_id"`
+	CorrelationID string `json:"correlation_id"`
+	Seq           int    `json:"seq"`
+	Delta         string `json:"delta"`
+}
+
+// AIReplyDoneEvent ends a streamed reply, Message is the whole reply as it was stored
+type AIReplyDoneEvent struct {
+	ChatID        string `json:"chat_id"`
+	CorrelationID string `json:"correlation_id"`
+	MessageID     string `json:"message_id"`
+	Message       string `json:"message"`
+}
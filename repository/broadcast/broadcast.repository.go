@@ -0,0 +1,16 @@
+package broadcast
+
+import "github.com/Meeyok-Chat/backend/models"
+
+const (
+	BroadcastChannel = "meeyok:websocket:broadcast"
+)
+
+type BroadcastHandler func(message models.BroadcastMessage)
+
+// BroadcastRepo fans websocket events out to every running instance.
+// Each event is published once, and every subscriber receives it.
+type BroadcastRepo interface {
+	Publish(message models.BroadcastMessage) error
+	Subscribe(handler BroadcastHandler)
+}
@@ -0,0 +1,52 @@
+package broadcast
+
+import (
+	"sync"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+const localBufferSize = 256
+
+// localBroadcastRepo delivers events inside a single process.
+// It is used when only one instance is running and in tests.
+type localBroadcastRepo struct {
+	messages chan models.BroadcastMessage
+	handlers []BroadcastHandler
+
+	sync.RWMutex
+}
+
+func NewLocalBroadcastRepo() BroadcastRepo {
+	r := &localBroadcastRepo{
+		messages: make(chan models.BroadcastMessage, localBufferSize),
+	}
+	go r.dispatch()
+	return r
+}
+
+func (r *localBroadcastRepo) Publish(message models.BroadcastMessage) error {
+	r.messages <- message
+	return nil
+}
+
+func (r *localBroadcastRepo) Subscribe(handler BroadcastHandler) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.handlers = append(r.handlers, handler)
+}
+
+// dispatch hands published messages to the subscribers on its own goroutine,
+// so publishers never run the handlers while holding their own locks
+func (r *localBroadcastRepo) dispatch() {
+	for message := range r.messages {
+		r.RLock()
+		handlers := r.handlers
+		r.RUnlock()
+
+		for _, handler := range handlers {
+			handler(message)
+		}
+	}
+}
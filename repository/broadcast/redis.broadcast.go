@@ -0,0 +1,52 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// redisBroadcastRepo relays events through a Redis pub/sub channel,
+// so every instance subscribed to it receives each event.
+type redisBroadcastRepo struct {
+	cache *configs.RedisClient
+}
+
+func NewRedisBroadcastRepo(cache *configs.RedisClient) BroadcastRepo {
+	return &redisBroadcastRepo{
+		cache: cache,
+	}
+}
+
+func (r *redisBroadcastRepo) Publish(message models.BroadcastMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return r.cache.Client.Publish(ctx, BroadcastChannel, data).Err()
+}
+
+func (r *redisBroadcastRepo) Subscribe(handler BroadcastHandler) {
+	pubsub := r.cache.Client.Subscribe(context.Background(), BroadcastChannel)
+
+	go func() {
+		defer pubsub.Close()
+
+		// Channel reconnects on its own and is only closed with pubsub
+		for msg := range pubsub.Channel() {
+			var message models.BroadcastMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				log.Printf("error unmarshalling broadcast message: %v", err)
+				continue
+			}
+			handler(message)
+		}
+	}()
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// localReplyCacheRepo keeps the deltas in memory, it is only correct on a single instance
+type localReplyCacheRepo struct {
+	replies map[string]map[int]string
+
+	sync.Mutex
+}
+
+func NewLocalReplyCacheRepo() ReplyCacheRepo {
+	return &localReplyCacheRepo{
+		replies: make(map[string]map[int]string),
+	}
+}
+
+func (r *localReplyCacheRepo) AppendDelta(correlationID string, seq int, delta string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.replies[correlationID] == nil {
+		r.replies[correlationID] = make(map[int]string)
+	}
+	r.replies[correlationID][seq] = delta
+	return nil
+}
+
+func (r *localReplyCacheRepo) GetReply(correlationID string, count int) (string, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	deltas := r.replies[correlationID]
+	if len(deltas) < count {
+		return "", models.ErrReplyIncomplete
+	}
+	var reply strings.Builder
+	for _, seq := range slices.Sorted(maps.Keys(deltas)) {
+		reply.WriteString(deltas[seq])
+	}
+	return reply.String(), nil
+}
+
+func (r *localReplyCacheRepo) DeleteReply(correlationID string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.replies, correlationID)
+	return nil
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// localUnreadCacheRepo keeps the counters in memory, it is only correct on a single instance
+type localUnreadCacheRepo struct {
+	counts map[string]map[string]int
+
+	sync.Mutex
+}
+
+func NewLocalUnreadCacheRepo() UnreadCacheRepo {
+	return &localUnreadCacheRepo{
+		counts: make(map[string]map[string]int),
+	}
+}
+
+func (r *localUnreadCacheRepo) GetUnreadCounts(userID string, chatIDs []string) (map[string]int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	result := make(map[string]int)
+	for _, chatID := range chatIDs {
+		if count, ok := r.counts[userID][chatID]; ok {
+			result[chatID] = count
+		}
+	}
+	return result, nil
+}
+
+func (r *localUnreadCacheRepo) SetUnreadCount(userID string, chatID string, count int) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.counts[userID] == nil {
+		r.counts[userID] = make(map[string]int)
+	}
+	r.counts[userID][chatID] = count
+	return nil
+}
+
+func (r *localUnreadCacheRepo) FillUnreadCount(userID string, chatID string, count int) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.counts[userID] == nil {
+		r.counts[userID] = make(map[string]int)
+	}
+	if _, ok := r.counts[userID][chatID]; !ok {
+		r.counts[userID][chatID] = count
+	}
+	return nil
+}
+
+func (r *localUnreadCacheRepo) IncrementUnreadCounts(chatID string, userIDs []string) (map[string]int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	result := make(map[string]int)
+	for _, userID := range userIDs {
+		count, ok := r.counts[userID][chatID]
+		if !ok {
+			continue
+		}
+		count = min(count+1, models.MaxUnreadCount)
+		r.counts[userID][chatID] = count
+		result[userID] = count
+	}
+	return result, nil
+}
+
+func (r *localUnreadCacheRepo) DeleteUnreadCounts(chatID string, userIDs []string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, userID := range userIDs {
+		delete(r.counts[userID], chatID)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// replyTTL drops the deltas of replies that were never finished
+const replyTTL = 10 * time.Minute
+
+// redisReplyCacheRepo keeps a hash of the deltas of every reply, keyed by their seq
+type redisReplyCacheRepo struct {
+	cache *configs.RedisClient
+}
+
+func NewRedisReplyCacheRepo(cache *configs.RedisClient) ReplyCacheRepo {
+	return &redisReplyCacheRepo{
+		cache: cache,
+	}
+}
+
+func replyKey(correlationID string) string {
+	return "meeyok:reply:" + correlationID
+}
+
+func (r *redisReplyCacheRepo) AppendDelta(correlationID string, seq int, delta string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := replyKey(correlationID)
+	pipe := r.cache.Client.TxPipeline()
+	pipe.HSet(ctx, key, strconv.Itoa(seq), delta)
+	pipe.Expire(ctx, key, replyTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisReplyCacheRepo) GetReply(correlationID string, count int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deltas, err := r.cache.Client.HGetAll(ctx, replyKey(correlationID)).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(deltas) < count {
+		return "", models.ErrReplyIncomplete
+	}
+	seqs := make([]int, 0, len(deltas))
+	for field := range deltas {
+		seq, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+
+	var reply strings.Builder
+	for _, seq := range seqs {
+		reply.WriteString(deltas[strconv.Itoa(seq)])
+	}
+	return reply.String(), nil
+}
+
+func (r *redisReplyCacheRepo) DeleteReply(correlationID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.cache.Client.Del(ctx, replyKey(correlationID)).Err()
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/go-redis/redis/v8"
+)
+
+// unreadTTL drops the counters of users who stopped using the app
+const unreadTTL = 7 * 24 * time.Hour
+
+// incrementScript only increments a counter that is already cached,
+// a missing counter would otherwise start again from one
+var incrementScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+	return -1
+end
+local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+if count > tonumber(ARGV[2]) then
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+	count = tonumber(ARGV[2])
+end
+return count
+`)
+
+// redisUnreadCacheRepo keeps a hash of the counters of every chat of a user
+type redisUnreadCacheRepo struct {
+	cache *configs.RedisClient
+}
+
+func NewRedisUnreadCacheRepo(cache *configs.RedisClient) UnreadCacheRepo {
+	return &redisUnreadCacheRepo{
+		cache: cache,
+	}
+}
+
+func unreadKey(userID string) string {
+	return "meeyok:unread:" + userID
+}
+
+func (r *redisUnreadCacheRepo) GetUnreadCounts(userID string, chatIDs []string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := make(map[string]int)
+	if len(chatIDs) == 0 {
+		return result, nil
+	}
+	values, err := r.cache.Client.HMGet(ctx, unreadKey(userID), chatIDs...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if count, err := strconv.Atoi(s); err == nil {
+			result[chatIDs[i]] = count
+		}
+	}
+	return result, nil
+}
+
+func (r *redisUnreadCacheRepo) SetUnreadCount(userID string, chatID string, count int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := unreadKey(userID)
+	pipe := r.cache.Client.TxPipeline()
+	pipe.HSet(ctx, key, chatID, count)
+	pipe.Expire(ctx, key, unreadTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisUnreadCacheRepo) FillUnreadCount(userID string, chatID string, count int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := unreadKey(userID)
+	pipe := r.cache.Client.TxPipeline()
+	pipe.HSetNX(ctx, key, chatID, count)
+	pipe.Expire(ctx, key, unreadTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisUnreadCacheRepo) IncrementUnreadCounts(chatID string, userIDs []string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := make(map[string]int)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	// Make sure the script is loaded, EVALSHA in a pipeline cannot fall back to EVAL
+	if err := incrementScript.Load(ctx, r.cache.Client).Err(); err != nil {
+		return nil, err
+	}
+	pipe := r.cache.Client.Pipeline()
+	cmds := make([]*redis.Cmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = incrementScript.EvalSha(ctx, pipe, []string{unreadKey(userID)}, chatID, models.MaxUnreadCount)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		if count, err := cmd.Int(); err == nil && count >= 0 {
+			result[userIDs[i]] = count
+		}
+	}
+	return result, nil
+}
+
+func (r *redisUnreadCacheRepo) DeleteUnreadCounts(chatID string, userIDs []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipe := r.cache.Client.Pipeline()
+	for _, userID := range userIDs {
+		pipe.HDel(ctx, unreadKey(userID), chatID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,11 @@
+package cache
+
+// ReplyCacheRepo buffers the deltas of the streamed replies of the AI until they are done,
+// the deltas of a reply can be received by any instance and in any order
+type ReplyCacheRepo interface {
+	AppendDelta(correlationID string, seq int, delta string) error
+	// GetReply returns the deltas of the reply joined in seq order,
+	// or models.ErrReplyIncomplete while fewer than deltas of them are buffered
+	GetReply(correlationID string, deltas int) (string, error)
+	DeleteReply(correlationID string) error
+}
@@ -0,0 +1,15 @@
+package cache
+
+// UnreadCacheRepo caches the unread counter of every chat of a user,
+// a counter that is not cached has to be counted from the messages again
+type UnreadCacheRepo interface {
+	// GetUnreadCounts returns the cached counters of the chats, chats without one are left out
+	GetUnreadCounts(userID string, chatIDs []string) (map[string]int, error)
+	SetUnreadCount(userID string, chatID string, count int) error
+	// FillUnreadCount caches a counted counter unless another one was cached since it was counted
+	FillUnreadCount(userID string, chatID string, count int) error
+	// IncrementUnreadCounts adds a message to the cached counters of the users in the chat
+	// and returns them, users without a cached counter are left out
+	IncrementUnreadCounts(chatID string, userIDs []string) (map[string]int, error)
+	DeleteUnreadCounts(chatID string, userIDs []string) error
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type aiRequestRepo struct {
+	aiRequestDb *mongo.Collection
+}
+
+type AIRequestRepo interface {
+	// Setup
+	CreateIndexes() error
+
+	// Get
+	GetAIRequest(id string) (models.AIRequest, error)
+	GetAIRequests(status string, before string, limit int) ([]models.AIRequest, error)
+	CountAIRequestsSince(userID string, since time.Time) (int64, error)
+
+	// Create
+	InsertAIRequest(request models.AIRequest) error
+
+	// Manage
+	ClaimExpiredRequest(now time.Time, timeout time.Duration) (models.AIRequest, bool, error)
+
+	// Update
+	SetAIRequestStatus(id string, status string, reason string, from ...string) (bool, error)
+	MarkAIRequestAnswered(id string, messageID primitive.ObjectID) error
+}
+
+func NewAIRequestRepo(aiRequestDb *mongo.Collection) AIRequestRepo {
+	return &aiRequestRepo{
+		aiRequestDb: aiRequestDb,
+	}
+}
+
+// CreateIndexes makes sure the monitor finds unanswered requests and the rate limit
+// counts the requests of a user without a scan
+func (r *aiRequestRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.aiRequestDb.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "sentAt", Value: 1}}},
+		{Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "requestedBy", Value: 1}, {Key: "createdAt", Value: -1}}},
+	})
+	return err
+}
+
+func (r *aiRequestRepo) GetAIRequest(id string) (models.AIRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	request := models.AIRequest{}
+	err := r.aiRequestDb.FindOne(ctx, bson.M{"_id": id}).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		return models.AIRequest{}, models.ErrAIRequestNotFound
+	} else if err != nil {
+		return models.AIRequest{}, err
+	}
+	return request, nil
+}
+
+// GetAIRequests returns a page of requests, newest first. status and before are optional,
+// correlation IDs are ObjectID hex strings so they sort in the order requests were made.
+func (r *aiRequestRepo) GetAIRequests(status string, before string, limit int) ([]models.AIRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if before != "" {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.aiRequestDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []models.AIRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// CountAIRequestsSince counts the requests the user made from the given time on
+func (r *aiRequestRepo) CountAIRequestsSince(userID string, since time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.aiRequestDb.CountDocuments(ctx, bson.M{"requestedBy": userID, "createdAt": bson.M{"$gte": since}})
+}
+
+func (r *aiRequestRepo) InsertAIRequest(request models.AIRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.aiRequestDb.InsertOne(ctx, request)
+	return err
+}
+
+// ClaimExpiredRequest hands a pending request that was not answered within the timeout
+// to a single instance, counting it as published again
+func (r *aiRequestRepo) ClaimExpiredRequest(now time.Time, timeout time.Duration) (models.AIRequest, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": models.AIRequestPending, "sentAt": bson.M{"$lt": now.Add(-timeout)}}
+	update := bson.M{
+		"$set": bson.M{"sentAt": now, "updatedAt": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "sentAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	request := models.AIRequest{}
+	err := r.aiRequestDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		return models.AIRequest{}, false, nil
+	} else if err != nil {
+		return models.AIRequest{}, false, err
+	}
+	return request, true, nil
+}
+
+// SetAIRequestStatus moves the request to the status, only from the given statuses when any
+// are given. It reports whether the request was in one of them.
+func (r *aiRequestRepo) SetAIRequestStatus(id string, status string, reason string, from ...string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	if len(from) > 0 {
+		filter["status"] = bson.M{"$in": from}
+	}
+	set := bson.M{"status": status, "updatedAt": time.Now()}
+	if reason != "" {
+		set["error"] = reason
+	}
+	result, err := r.aiRequestDb.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *aiRequestRepo) MarkAIRequestAnswered(id string, messageID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":         models.AIRequestAnswered,
+			"replyMessageId": messageID,
+			"updatedAt":      time.Now(),
+		},
+		"$unset": bson.M{"error": ""},
+	}
+	_, err := r.aiRequestDb.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
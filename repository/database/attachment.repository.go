@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type attachmentRepo struct {
+	attachmentDb *mongo.Collection
+}
+
+type AttachmentRepo interface {
+	// Get
+	GetAttachment(chatID string, attachmentID string) (models.Attachment, error)
+
+	// Create
+	InsertAttachment(attachment models.Attachment) error
+
+	// Update
+	ClaimAttachments(chatID primitive.ObjectID, userID string, attachmentIDs []primitive.ObjectID, messageID primitive.ObjectID) ([]models.Attachment, error)
+	ReleaseAttachments(messageID primitive.ObjectID) error
+
+	// Delete
+	DeleteAttachments(attachmentIDs []primitive.ObjectID) error
+}
+
+func NewAttachmentRepo(attachmentDb *mongo.Collection) AttachmentRepo {
+	return &attachmentRepo{
+		attachmentDb: attachmentDb,
+	}
+}
+
+func (r *attachmentRepo) GetAttachment(chatID string, attachmentID string) (models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.Attachment{}, models.ErrChatNotFound
+	}
+	attachmentObjID, err := primitive.ObjectIDFromHex(attachmentID)
+	if err != nil {
+		return models.Attachment{}, models.ErrAttachmentNotFound
+	}
+
+	attachment := models.Attachment{}
+	filter := bson.M{"_id": attachmentObjID, "chatId": objID}
+	err = r.attachmentDb.FindOne(ctx, filter).Decode(&attachment)
+	if err == mongo.ErrNoDocuments {
+		return models.Attachment{}, models.ErrAttachmentNotFound
+	} else if err != nil {
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (r *attachmentRepo) InsertAttachment(attachment models.Attachment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.attachmentDb.InsertOne(ctx, attachment)
+	return err
+}
+
+// ClaimAttachments binds attachments the user uploaded into the chat to the message,
+// it fails with models.ErrAttachmentNotFound when any of them is unknown or already sent
+func (r *attachmentRepo) ClaimAttachments(chatID primitive.ObjectID, userID string, attachmentIDs []primitive.ObjectID, messageID primitive.ObjectID) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":        bson.M{"$in": attachmentIDs},
+		"chatId":     chatID,
+		"uploadedBy": userID,
+		"messageId":  bson.M{"$exists": false},
+	}
+	cursor, err := r.attachmentDb.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	attachments := []models.Attachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	if len(attachments) != len(attachmentIDs) {
+		return nil, models.ErrAttachmentNotFound
+	}
+
+	result, err := r.attachmentDb.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"messageId": messageID}})
+	if err != nil {
+		return nil, err
+	}
+	// Another message claimed some of them in the meantime, the others are free again
+	if result.ModifiedCount != int64(len(attachmentIDs)) {
+		if err := r.ReleaseAttachments(messageID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrAttachmentNotFound
+	}
+
+	// Keep the order the attachments were given in
+	byID := make(map[primitive.ObjectID]models.Attachment, len(attachments))
+	for _, attachment := range attachments {
+		attachment.MessageID = &messageID
+		byID[attachment.ID] = attachment
+	}
+	claimed := make([]models.Attachment, 0, len(attachmentIDs))
+	for _, id := range attachmentIDs {
+		claimed = append(claimed, byID[id])
+	}
+	return claimed, nil
+}
+
+// ReleaseAttachments frees the attachments claimed by a message that was never stored
+func (r *attachmentRepo) ReleaseAttachments(messageID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.attachmentDb.UpdateMany(ctx, bson.M{"messageId": messageID}, bson.M{"$unset": bson.M{"messageId": ""}})
+	return err
+}
+
+func (r *attachmentRepo) DeleteAttachments(attachmentIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.attachmentDb.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": attachmentIDs}})
+	return err
+}
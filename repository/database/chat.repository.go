@@ -22,30 +22,49 @@ type chatRepo struct {
 type ChatRepo interface {
 	// New
 	NewChat(id primitive.ObjectID) models.Chat
-	NewMessage(msg string, from string) models.Message
+
+	// Setup
+	CreateIndexes() error
+	BackfillOwners() error
 
 	// Get
 	GetChats() ([]models.Chat, error)
 	GetChatByID(id string) (models.Chat, error)
+	GetChatUsers(id string) ([]string, error)
+	GetUserChatIDs(userID string) ([]string, error)
+	GetDirectChat(userID string, otherUserID string) (models.Chat, error)
+	GetMeeyokChat(userID string) (models.Chat, error)
 	GetGroupChats(userID string) ([]models.Chat, error)
 	GetFriendChats(userID string) ([]models.Chat, error)
 	GetNonFriendChats(userID string) ([]models.Chat, error)
 
 	// Create
 	CreateChat(chat models.Chat) (models.Chat, error)
+	CreateDirectChat(userID string, otherUserID string) (models.Chat, bool, error)
+	CreateMeeyokChat(userID string) (models.Chat, bool, error)
 
 	// Manage
 	AddUsersToChat(chatID string, users []string) error
-	AppendMessage(chatID string, message models.Message) error
-	UploadChat(chat models.Chat) error
+	RemoveUserFromChat(chatID string, userID string, newOwnerID string) error
+	SetAdmin(chatID string, userID string, admin bool) error
+	TransferOwnership(chatID string, ownerID string, newOwnerID string) error
+	PinMessage(chatID string, pin models.Pin, max int) (bool, error)
+	UnpinMessage(chatID string, messageID primitive.ObjectID) (bool, error)
+	RemovePins(chatID string, messageIDs []primitive.ObjectID) error
 
 	// Update
 	UpdateChat(chat models.Chat) error
+	SetMessageTTL(chatID string, ttl int64) error
+	SetAISettings(chatID string, disabled bool, persona string) error
 
 	// Delete
 	DeleteChat(id string) error
 }
 
+// withoutMessages leaves out the messages still embedded in chats created before
+// messages moved to their own collection, see cmd/migrate-messages
+var withoutMessages = bson.M{"messages": 0}
+
 func NewChatRepo(chatDb *mongo.Collection, userDb *mongo.Collection, friendshipDb *mongo.Collection) ChatRepo {
 	return &chatRepo{
 		chatDb:       chatDb,
@@ -64,14 +83,42 @@ func (r *chatRepo) NewChat(id primitive.ObjectID) models.Chat {
 	return chat
 }
 
-func (r *chatRepo) NewMessage(msg string, from string) models.Message {
-	message := models.Message{
-		ID:        primitive.NewObjectID(),
-		From:      from,
-		Message:   msg,
-		CreatedAt: time.Now(),
+// CreateIndexes makes sure two users never share more than one individual chat
+func (r *chatRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.chatDb.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "pairKey", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"pairKey": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "users", Value: 1}, {Key: "type", Value: 1}}},
+	})
+	return err
+}
+
+// BackfillOwners stores an owner on the groups created before roles existed, their first member
+func (r *chatRepo) BackfillOwners() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"type":    models.GroupChatType,
+		"owner":   bson.M{"$in": bson.A{nil, ""}},
+		"users.0": bson.M{"$exists": true},
+	}
+	update := bson.A{bson.M{"$set": bson.M{"owner": bson.M{"$arrayElemAt": bson.A{"$users", 0}}}}}
+	result, err := r.chatDb.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
 	}
-	return message
+	if result.ModifiedCount > 0 {
+		log.Printf("Stored the owner of %d group chats", result.ModifiedCount)
+	}
+	return nil
 }
 
 func (r *chatRepo) GetChats() ([]models.Chat, error) {
@@ -79,7 +126,9 @@ func (r *chatRepo) GetChats() ([]models.Chat, error) {
 	defer cancel()
 
 	filter := bson.M{}
-	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updatedAt", Value: -1}}).
+		SetProjection(withoutMessages)
 
 	cursor, err := r.chatDb.Find(ctx, filter, opts)
 	if err != nil {
@@ -99,13 +148,115 @@ func (r *chatRepo) GetChatByID(id string) (models.Chat, error) {
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Fatalf("Invalid ObjectID: %v", err)
+		return models.Chat{}, models.ErrChatNotFound
 	}
 
 	chat := models.Chat{}
 	filter := bson.M{"_id": objID}
-	err = r.chatDb.FindOne(ctx, filter).Decode(&chat)
+	opts := options.FindOne().SetProjection(withoutMessages)
+	err = r.chatDb.FindOne(ctx, filter, opts).Decode(&chat)
+	if err == mongo.ErrNoDocuments {
+		return models.Chat{}, models.ErrChatNotFound
+	} else if err != nil {
+		return models.Chat{}, err
+	}
+	return chat, nil
+}
+
+// GetChatUsers returns the members of the chat without loading its messages
+func (r *chatRepo) GetChatUsers(id string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
+		return nil, models.ErrChatNotFound
+	}
+
+	chat := models.Chat{}
+	filter := bson.M{"_id": objID}
+	opts := options.FindOne().SetProjection(bson.M{"users": 1})
+	err = r.chatDb.FindOne(ctx, filter, opts).Decode(&chat)
+	if err == mongo.ErrNoDocuments {
+		return nil, models.ErrChatNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return chat.Users, nil
+}
+
+// GetUserChatIDs returns the IDs of every chat the user is a member of
+func (r *chatRepo) GetUserChatIDs(userID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"users": userID}
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.chatDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var chats []models.Chat
+	if err = cursor.All(ctx, &chats); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(chats))
+	for _, chat := range chats {
+		ids = append(ids, chat.ID.Hex())
+	}
+	return ids, nil
+}
+
+// GetDirectChat returns the individual chat of the two users. Chats created before
+// pair keys existed are found by their members and get their pair key on the way.
+func (r *chatRepo) GetDirectChat(userID string, otherUserID string) (models.Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pairKey := models.DirectPairKey(userID, otherUserID)
+	chat := models.Chat{}
+	opts := options.FindOne().SetProjection(withoutMessages)
+	err := r.chatDb.FindOne(ctx, bson.M{"pairKey": pairKey}, opts).Decode(&chat)
+	if err == nil {
+		return chat, nil
+	} else if err != mongo.ErrNoDocuments {
+		return models.Chat{}, err
+	}
+
+	filter := bson.M{
+		"type":  models.IndividualChatType,
+		"users": bson.M{"$all": bson.A{userID, otherUserID}, "$size": 2},
+	}
+	opts.SetSort(bson.D{{Key: "_id", Value: 1}})
+	err = r.chatDb.FindOne(ctx, filter, opts).Decode(&chat)
+	if err == mongo.ErrNoDocuments {
+		return models.Chat{}, models.ErrChatNotFound
+	} else if err != nil {
+		return models.Chat{}, err
+	}
+
+	// Losing the race to another request claiming the key is fine, it found the same chat
+	_, err = r.chatDb.UpdateOne(ctx, bson.M{"_id": chat.ID}, bson.M{"$set": bson.M{"pairKey": pairKey}})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return models.Chat{}, err
+	}
+	chat.PairKey = pairKey
+	return chat, nil
+}
+
+// GetMeeyokChat returns the chat of the user with Meeyok AI
+func (r *chatRepo) GetMeeyokChat(userID string) (models.Chat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chat := models.Chat{}
+	opts := options.FindOne().SetProjection(withoutMessages)
+	err := r.chatDb.FindOne(ctx, bson.M{"pairKey": models.MeeyokPairKey(userID)}, opts).Decode(&chat)
+	if err == mongo.ErrNoDocuments {
+		return models.Chat{}, models.ErrChatNotFound
+	} else if err != nil {
 		return models.Chat{}, err
 	}
 	return chat, nil
@@ -117,7 +268,7 @@ func (r *chatRepo) GetGroupChats(userID string) ([]models.Chat, error) {
 
 	var chats []models.Chat
 	filter := bson.M{"users": userID, "type": models.GroupChatType}
-	cursor, err := r.chatDb.Find(ctx, filter)
+	cursor, err := r.chatDb.Find(ctx, filter, options.Find().SetProjection(withoutMessages))
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +288,7 @@ func (r *chatRepo) GetFriendChats(userID string) ([]models.Chat, error) {
 		"type":  models.IndividualChatType,
 	}
 
-	cursor, err := r.chatDb.Find(ctx, filter)
+	cursor, err := r.chatDb.Find(ctx, filter, options.Find().SetProjection(withoutMessages))
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +331,7 @@ func (r *chatRepo) GetNonFriendChats(userID string) ([]models.Chat, error) {
 		"type":  models.IndividualChatType,
 	}
 
-	cursor, err := r.chatDb.Find(ctx, filter)
+	cursor, err := r.chatDb.Find(ctx, filter, options.Find().SetProjection(withoutMessages))
 	if err != nil {
 		return nil, err
 	}
@@ -251,24 +402,75 @@ func (r *chatRepo) CreateChat(chat models.Chat) (models.Chat, error) {
 	return chat, nil
 }
 
+// CreateDirectChat creates the individual chat of the two users unless it exists,
+// created is false when the chat of the pair was already there
+func (r *chatRepo) CreateDirectChat(userID string, otherUserID string) (models.Chat, bool, error) {
+	chat, err := r.GetDirectChat(userID, otherUserID)
+	if err == nil {
+		return chat, false, nil
+	} else if err != models.ErrChatNotFound {
+		return models.Chat{}, false, err
+	}
+
+	chat, err = r.CreateChat(models.Chat{
+		Users:   []string{userID, otherUserID},
+		Type:    models.IndividualChatType,
+		PairKey: models.DirectPairKey(userID, otherUserID),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		// Another request created it in the meantime
+		chat, err = r.GetDirectChat(userID, otherUserID)
+		return chat, false, err
+	} else if err != nil {
+		return models.Chat{}, false, err
+	}
+	return chat, true, nil
+}
+
+// CreateMeeyokChat creates the chat of the user with Meeyok AI unless it exists,
+// created is false when the user already had one
+func (r *chatRepo) CreateMeeyokChat(userID string) (models.Chat, bool, error) {
+	chat, err := r.GetMeeyokChat(userID)
+	if err == nil {
+		return chat, false, nil
+	} else if err != models.ErrChatNotFound {
+		return models.Chat{}, false, err
+	}
+
+	chat, err = r.CreateChat(models.Chat{
+		Name:    models.MeeyokAI,
+		Users:   []string{userID},
+		Type:    models.MeeyokChatType,
+		PairKey: models.MeeyokPairKey(userID),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		// Another request created it in the meantime
+		chat, err = r.GetMeeyokChat(userID)
+		return chat, false, err
+	} else if err != nil {
+		return models.Chat{}, false, err
+	}
+	return chat, true, nil
+}
+
 func (r *chatRepo) AddUsersToChat(chatID string, users []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(chatID)
 	if err != nil {
-		log.Fatalf("Invalid ObjectID: %v", err)
+		return models.ErrChatNotFound
 	}
 
 	// Check chat type
 	var chat models.Chat
-	err = r.chatDb.FindOne(ctx, bson.M{"_id": objID}).Decode(&chat)
+	err = r.chatDb.FindOne(ctx, bson.M{"_id": objID}, options.FindOne().SetProjection(withoutMessages)).Decode(&chat)
 	if err != nil {
 		return fmt.Errorf("chat not found: %v", err)
 	}
 
-	if chat.Type == models.IndividualChatType {
-		return fmt.Errorf("cannot add users to Individual chat")
+	if chat.Type != models.GroupChatType {
+		return fmt.Errorf("cannot add users to %s chat", chat.Type)
 	}
 
 	// Add new users to group chat
@@ -288,58 +490,200 @@ func (r *chatRepo) AddUsersToChat(chatID string, users []string) error {
 	return nil
 }
 
-func (r *chatRepo) AppendMessage(chatID string, message models.Message) error {
+// RemoveUserFromChat takes the user out of the members and the admins of the chat. An owner
+// leaving hands the chat over to newOwnerID in the same update, the leaving owner is not kept on as an admin.
+func (r *chatRepo) RemoveUserFromChat(chatID string, userID string, newOwnerID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(chatID)
 	if err != nil {
-		log.Fatalf("Invalid ObjectID: %v", err)
+		return models.ErrChatNotFound
 	}
 
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": objID, "users": userID}
 	update := bson.M{
-		"$push": bson.M{
-			"messages": message,
-		},
+		"$pull": bson.M{"users": userID, "admins": userID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	if newOwnerID != "" {
+		filter = bson.M{"_id": objID, "owner": userID, "users": bson.M{"$all": bson.A{userID, newOwnerID}}}
+		update = bson.M{
+			"$pull": bson.M{"users": userID, "admins": bson.M{"$in": bson.A{userID, newOwnerID}}},
+			"$set":  bson.M{"owner": newOwnerID, "updatedAt": time.Now()},
+		}
 	}
-	updateOptions := options.Update().SetUpsert(true)
-	_, err = r.chatDb.UpdateOne(ctx, filter, update, updateOptions)
-	// log.Println("Uploading chat ", chatID)
+	result, err := r.chatDb.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return models.ErrMemberNotFound
+	}
 	return nil
 }
 
-func (r *chatRepo) UploadChat(chat models.Chat) error {
+// SetAdmin adds the member to the admins of the chat, or removes them
+func (r *chatRepo) SetAdmin(chatID string, userID string, admin bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": chat.ID}
-	update := bson.M{
-		"$push": bson.M{
-			"messages": bson.M{
-				"$each": chat.Messages,
-			},
-		},
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	filter := bson.M{"_id": objID, "users": userID}
+	update := bson.M{"$pull": bson.M{"admins": userID}}
+	if admin {
+		update = bson.M{"$addToSet": bson.M{"admins": userID}}
+	}
+	result, err := r.chatDb.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
 	}
-	updateOptions := options.Update().SetUpsert(true)
-	_, err := r.chatDb.UpdateOne(ctx, filter, update, updateOptions)
-	log.Println("Uploading chat ", chat.ID.Hex())
+	if result.MatchedCount == 0 {
+		return models.ErrMemberNotFound
+	}
+	return nil
+}
+
+// TransferOwnership makes another member the owner of the chat,
+// the previous owner becomes an admin and the new owner stops being one
+func (r *chatRepo) TransferOwnership(chatID string, ownerID string, newOwnerID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	// Only the current owner can hand the chat over, not one who was already replaced
+	filter := bson.M{"_id": objID, "owner": ownerID, "users": newOwnerID}
+	admins := bson.M{"$setUnion": bson.A{
+		bson.M{"$setDifference": bson.A{bson.M{"$ifNull": bson.A{"$admins", bson.A{}}}, bson.A{newOwnerID}}},
+		bson.A{ownerID},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"owner":     newOwnerID,
+		"admins":    admins,
+		"updatedAt": time.Now(),
+	}}}
+	result, err := r.chatDb.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return r.transferFailure(ctx, objID, newOwnerID)
+	}
 	return nil
 }
 
+// transferFailure tells apart why a transfer matched no chat, the new owner is no member
+// or the ownership changed hands in the meantime
+func (r *chatRepo) transferFailure(ctx context.Context, chatID primitive.ObjectID, newOwnerID string) error {
+	count, err := r.chatDb.CountDocuments(ctx, bson.M{"_id": chatID, "users": newOwnerID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return models.ErrMemberNotFound
+	}
+	return models.ErrPermissionDenied
+}
+
+// PinMessage adds the pin to the chat unless the message is already pinned,
+// it returns models.ErrTooManyPins when the chat already has max pins
+func (r *chatRepo) PinMessage(chatID string, pin models.Pin, max int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return false, models.ErrChatNotFound
+	}
+
+	// Checking the cap in the filter keeps concurrent pins from going over it
+	filter := bson.M{
+		"_id":                         objID,
+		"pins.messageId":              bson.M{"$ne": pin.MessageID},
+		fmt.Sprintf("pins.%d", max-1): bson.M{"$exists": false},
+	}
+	update := bson.M{"$push": bson.M{"pins": pin}}
+	result, err := r.chatDb.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+
+	// Find out why nothing was pinned
+	chat := models.Chat{}
+	opts := options.FindOne().SetProjection(bson.M{"pins": 1})
+	err = r.chatDb.FindOne(ctx, bson.M{"_id": objID}, opts).Decode(&chat)
+	if err == mongo.ErrNoDocuments {
+		return false, models.ErrChatNotFound
+	} else if err != nil {
+		return false, err
+	}
+	for _, p := range chat.Pins {
+		if p.MessageID == pin.MessageID {
+			return false, nil
+		}
+	}
+	return false, models.ErrTooManyPins
+}
+
+// UnpinMessage removes the pin of the message from the chat, and reports whether it was pinned
+func (r *chatRepo) UnpinMessage(chatID string, messageID primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return false, models.ErrChatNotFound
+	}
+
+	update := bson.M{"$pull": bson.M{"pins": bson.M{"messageId": messageID}}}
+	result, err := r.chatDb.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, models.ErrChatNotFound
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// RemovePins drops the pins of messages that no longer exist
+func (r *chatRepo) RemovePins(chatID string, messageIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	update := bson.M{"$pull": bson.M{"pins": bson.M{"messageId": bson.M{"$in": messageIDs}}}}
+	_, err = r.chatDb.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
 func (r *chatRepo) UpdateChat(chat models.Chat) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	chat.UpdatedAt = time.Now()
+	// Only the chat details are updated, members and messages have their own operations
 	filter := bson.M{"_id": chat.ID}
-	update := bson.M{"$set": chat}
+	update := bson.M{
+		"$set": bson.M{
+			"name":      chat.Name,
+			"updatedAt": time.Now(),
+		},
+	}
 	_, err := r.chatDb.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
@@ -347,13 +691,42 @@ func (r *chatRepo) UpdateChat(chat models.Chat) error {
 	return nil
 }
 
+// SetMessageTTL sets the lifetime of the messages sent into the chat from now on, zero turns it off
+func (r *chatRepo) SetMessageTTL(chatID string, ttl int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	update := bson.M{
+		"$set": bson.M{"messageTtl": ttl, "updatedAt": time.Now()},
+	}
+	if ttl <= 0 {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"messageTtl": ""},
+		}
+	}
+	result, err := r.chatDb.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrChatNotFound
+	}
+	return nil
+}
+
 func (r *chatRepo) DeleteChat(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Fatalf("Invalid ObjectID: %v", err)
+		return models.ErrChatNotFound
 	}
 
 	filter := bson.M{"_id": objID}
@@ -366,3 +739,39 @@ func (r *chatRepo) DeleteChat(id string) error {
 	}
 	return nil
 }
+
+// SetAISettings turns Meeyok AI on or off in the chat and sets the persona it follows, an empty persona clears it
+func (r *chatRepo) SetAISettings(chatID string, disabled bool, persona string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	unset := bson.M{}
+	if disabled {
+		set["aiDisabled"] = true
+	} else {
+		unset["aiDisabled"] = ""
+	}
+	if persona != "" {
+		set["aiPersona"] = persona
+	} else {
+		unset["aiPersona"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := r.chatDb.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrChatNotFound
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type deadLetterRepo struct {
+	deadLetterDb *mongo.Collection
+}
+
+type DeadLetterRepo interface {
+	// Get
+	GetDeadLetter(id string) (models.DeadLetter, error)
+	GetDeadLetters(query models.DeadLetterQuery) ([]models.DeadLetter, bool, error)
+
+	// Create
+	InsertDeadLetter(deadLetter models.DeadLetter) error
+
+	// Update
+	MarkDeadLetterReplayed(id primitive.ObjectID) (time.Time, error)
+	UnmarkDeadLetterReplayed(id primitive.ObjectID) error
+}
+
+func NewDeadLetterRepo(deadLetterDb *mongo.Collection) DeadLetterRepo {
+	return &deadLetterRepo{
+		deadLetterDb: deadLetterDb,
+	}
+}
+
+func (r *deadLetterRepo) GetDeadLetter(id string) (models.DeadLetter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.DeadLetter{}, models.ErrDeadLetterNotFound
+	}
+
+	deadLetter := models.DeadLetter{}
+	err = r.deadLetterDb.FindOne(ctx, bson.M{"_id": objID}).Decode(&deadLetter)
+	if err == mongo.ErrNoDocuments {
+		return models.DeadLetter{}, models.ErrDeadLetterNotFound
+	} else if err != nil {
+		return models.DeadLetter{}, err
+	}
+	return deadLetter, nil
+}
+
+// GetDeadLetters returns a page of dead letters, newest first, and whether there are older ones
+func (r *deadLetterRepo) GetDeadLetters(query models.DeadLetterQuery) ([]models.DeadLetter, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if query.Pending {
+		filter["replayedAt"] = bson.M{"$exists": false}
+	}
+	if query.Before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(query.Before)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid before cursor: %v", err)
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+	cursor, err := r.deadLetterDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	deadLetters := []models.DeadLetter{}
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, false, err
+	}
+	hasMore := len(deadLetters) > query.Limit
+	if hasMore {
+		deadLetters = deadLetters[:query.Limit]
+	}
+	return deadLetters, hasMore, nil
+}
+
+func (r *deadLetterRepo) InsertDeadLetter(deadLetter models.DeadLetter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.deadLetterDb.InsertOne(ctx, deadLetter)
+	return err
+}
+
+// MarkDeadLetterReplayed claims the replay of a dead letter and returns when it was replayed,
+// it fails with models.ErrDeadLetterReplayed when it was replayed already
+func (r *deadLetterRepo) MarkDeadLetterReplayed(id primitive.ObjectID) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"_id": id, "replayedAt": bson.M{"$exists": false}}
+	result, err := r.deadLetterDb.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"replayedAt": now}})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if result.MatchedCount == 0 {
+		return time.Time{}, models.ErrDeadLetterReplayed
+	}
+	return now, nil
+}
+
+// UnmarkDeadLetterReplayed gives up the replay of a dead letter that could not be sent
+func (r *deadLetterRepo) UnmarkDeadLetterReplayed(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.deadLetterDb.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"replayedAt": ""}})
+	return err
+}
@@ -0,0 +1,655 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type messageRepo struct {
+	messageDb *mongo.Collection
+}
+
+type MessageRepo interface {
+	// New
+	NewMessage(chatID primitive.ObjectID, msg string, from string) models.Message
+
+	// Setup
+	CreateIndexes() error
+
+	// Get
+	GetMessages(chatID string, query models.MessageQuery) ([]models.Message, bool, error)
+	GetMessage(chatID string, messageID string) (models.Message, error)
+	GetMessagesByIDs(chatID string, messageIDs []primitive.ObjectID) ([]models.Message, error)
+	GetUnreadMentions(userID string, chatIDs []string, lastRead map[string]primitive.ObjectID, query models.MessageQuery) ([]models.Message, bool, error)
+	CountUnread(chatID string, userID string, lastRead primitive.ObjectID) (int, error)
+	CountUnreadByChat(userID string, chatIDs []string, lastRead map[string]primitive.ObjectID) (map[string]int, error)
+	GetExpiredMessages(now time.Time, limit int) ([]models.Message, error)
+
+	// Create
+	InsertMessage(message models.Message) error
+
+	// Manage
+	MarkMessages(chatID string, userID string, afterID primitive.ObjectID, upToMessageID string, status string) ([]models.Message, error)
+
+	// Update
+	EditMessage(message models.Message, text string, mentions []models.Mention) (models.Message, error)
+	DeleteMessageForEveryone(message models.Message) (models.Message, error)
+	DeleteMessageForUser(message models.Message, userID string) error
+	AddReaction(message models.Message, userID string, emoji string) (models.Message, error)
+	RemoveReaction(message models.Message, userID string, emoji string) (models.Message, error)
+
+	// Delete
+	DeleteChatMessages(chatID string) error
+	DeleteMessages(messages []models.Message) error
+}
+
+func NewMessageRepo(messageDb *mongo.Collection) MessageRepo {
+	return &messageRepo{
+		messageDb: messageDb,
+	}
+}
+
+func (r *messageRepo) NewMessage(chatID primitive.ObjectID, msg string, from string) models.Message {
+	message := models.Message{
+		ID:        primitive.NewObjectID(),
+		ChatID:    chatID,
+		From:      from,
+		Message:   msg,
+		CreatedAt: time.Now(),
+	}
+	return message
+}
+
+// CreateIndexes makes sure the messages of a chat can be paged by time,
+// message IDs are ObjectIDs so their order is the order they were created in
+func (r *messageRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.messageDb.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "createdat", Value: -1}}},
+		{Keys: bson.D{{Key: "threadId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "mentions.userId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	return err
+}
+
+// unexpired matches the messages that did not expire yet, expired ones stay hidden until they are swept
+func unexpired() bson.M {
+	return bson.M{"$not": bson.M{"$lte": time.Now()}}
+}
+
+// GetMessages returns a page of messages of the chat in chronological order,
+// and whether there are more messages beyond the page in the direction it was read
+func (r *messageRepo) GetMessages(chatID string, query models.MessageQuery) ([]models.Message, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return nil, false, models.ErrChatNotFound
+	}
+
+	filter := bson.M{"chatId": objID, "expiresAt": unexpired()}
+	if query.Viewer != "" {
+		filter["deletedFor"] = bson.M{"$ne": query.Viewer}
+	}
+	if query.ThreadID != "" {
+		threadID, err := primitive.ObjectIDFromHex(query.ThreadID)
+		if err != nil {
+			return nil, false, models.ErrMessageNotFound
+		}
+		filter["threadId"] = threadID
+	}
+	// Read backwards from the newest message, unless paging forward from After
+	sort := -1
+	if query.After != "" {
+		afterID, err := primitive.ObjectIDFromHex(query.After)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid after cursor: %v", err)
+		}
+		filter["_id"] = bson.M{"$gt": afterID}
+		sort = 1
+	} else if query.Before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(query.Before)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid before cursor: %v", err)
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: sort}}).
+		SetSkip(int64(query.Skip))
+	// Read one more than asked to know if there are more, a zero limit reads everything
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit + 1))
+	}
+
+	cursor, err := r.messageDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := query.Limit > 0 && len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+	if sort == -1 {
+		slices.Reverse(messages)
+	}
+	return messages, hasMore, nil
+}
+
+func (r *messageRepo) GetMessage(chatID string, messageID string) (models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.Message{}, models.ErrChatNotFound
+	}
+	messageObjID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return models.Message{}, models.ErrMessageNotFound
+	}
+
+	message := models.Message{}
+	filter := bson.M{"_id": messageObjID, "chatId": objID, "expiresAt": unexpired()}
+	err = r.messageDb.FindOne(ctx, filter).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return models.Message{}, models.ErrMessageNotFound
+	} else if err != nil {
+		return models.Message{}, err
+	}
+	return message, nil
+}
+
+// GetMessagesByIDs returns the messages of the chat with the given IDs in no particular order,
+// IDs of missing messages are skipped
+func (r *messageRepo) GetMessagesByIDs(chatID string, messageIDs []primitive.ObjectID) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return nil, models.ErrChatNotFound
+	}
+
+	filter := bson.M{"chatId": objID, "_id": bson.M{"$in": messageIDs}, "expiresAt": unexpired()}
+	cursor, err := r.messageDb.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetUnreadMentions returns a page of the messages of the chats mentioning the user
+// after the last message the user read in each chat, newest first. lastRead holds the
+// read markers by chat ID, chats without one are unread from the start.
+func (r *messageRepo) GetUnreadMentions(userID string, chatIDs []string, lastRead map[string]primitive.ObjectID, query models.MessageQuery) ([]models.Message, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	unread := unreadFilters(chatIDs, lastRead)
+	if len(unread) == 0 {
+		return []models.Message{}, false, nil
+	}
+
+	filter := bson.M{
+		"mentions.userId": userID,
+		"$or":             unread,
+		"deleted":         bson.M{"$ne": true},
+		"deletedFor":      bson.M{"$ne": userID},
+		"expiresAt":       unexpired(),
+	}
+	if query.Before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(query.Before)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid before cursor: %v", err)
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit + 1))
+	}
+
+	cursor, err := r.messageDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := query.Limit > 0 && len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+	return messages, hasMore, nil
+}
+
+// unreadFilters matches the messages of the chats after the read marker of each chat,
+// chats without a marker are matched from the start
+func unreadFilters(chatIDs []string, lastRead map[string]primitive.ObjectID) bson.A {
+	unread := bson.A{}
+	neverRead := []primitive.ObjectID{}
+	for _, chatID := range chatIDs {
+		objID, err := primitive.ObjectIDFromHex(chatID)
+		if err != nil {
+			continue
+		}
+		if marker, ok := lastRead[chatID]; ok && !marker.IsZero() {
+			unread = append(unread, bson.M{"chatId": objID, "_id": bson.M{"$gt": marker}})
+		} else {
+			neverRead = append(neverRead, objID)
+		}
+	}
+	if len(neverRead) > 0 {
+		unread = append(unread, bson.M{"chatId": bson.M{"$in": neverRead}})
+	}
+	return unread
+}
+
+// CountUnread counts the messages of the others after the last message the user read, leaving out system messages,
+// up to models.MaxUnreadCount. A zero lastRead counts from the start of the chat.
+func (r *messageRepo) CountUnread(chatID string, userID string, lastRead primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return 0, models.ErrChatNotFound
+	}
+
+	filter := bson.M{
+		"chatId":     objID,
+		"from":       bson.M{"$nin": bson.A{userID, models.SystemSender}},
+		"deleted":    bson.M{"$ne": true},
+		"deletedFor": bson.M{"$ne": userID},
+		"expiresAt":  unexpired(),
+	}
+	if !lastRead.IsZero() {
+		filter["_id"] = bson.M{"$gt": lastRead}
+	}
+
+	count, err := r.messageDb.CountDocuments(ctx, filter, options.Count().SetLimit(models.MaxUnreadCount))
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// CountUnreadByChat counts the unread messages of the user in every chat with a single aggregation,
+// like CountUnread. Chats without unread messages are left out.
+func (r *messageRepo) CountUnreadByChat(userID string, chatIDs []string, lastRead map[string]primitive.ObjectID) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	counts := make(map[string]int)
+	unread := unreadFilters(chatIDs, lastRead)
+	if len(unread) == 0 {
+		return counts, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or":        unread,
+			"from":       bson.M{"$nin": bson.A{userID, models.SystemSender}},
+			"deleted":    bson.M{"$ne": true},
+			"deletedFor": bson.M{"$ne": userID},
+			"expiresAt":  unexpired(),
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$chatId", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.messageDb.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ChatID primitive.ObjectID `bson:"_id"`
+		Count  int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		counts[result.ChatID.Hex()] = min(result.Count, models.MaxUnreadCount)
+	}
+	return counts, nil
+}
+
+func (r *messageRepo) InsertMessage(message models.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.messageDb.InsertOne(ctx, message)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrMessageExists
+	} else if err != nil {
+		return err
+	}
+
+	// Keep the reply count of the thread root up to date
+	if message.ThreadID != nil {
+		_, err = r.messageDb.UpdateOne(ctx, bson.M{"_id": *message.ThreadID}, bson.M{"$inc": bson.M{"replyCount": 1}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkMessages records a delivered or read receipt of the user on the messages after afterID
+// up to and including upToMessageID, and returns the messages that were newly marked.
+// At most models.MaxReceiptMessages of the newest messages are marked, a zero afterID starts
+// at the beginning of the chat. A read receipt also marks the message as delivered.
+func (r *messageRepo) MarkMessages(chatID string, userID string, afterID primitive.ObjectID, upToMessageID string, status string) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return nil, models.ErrChatNotFound
+	}
+	upToID, err := primitive.ObjectIDFromHex(upToMessageID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message id: %v", err)
+	}
+
+	count, err := r.messageDb.CountDocuments(ctx, bson.M{"_id": upToID, "chatId": objID})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("message not found in chat")
+	}
+
+	field := "deliveredTo"
+	if status == models.ReceiptRead {
+		field = "readBy"
+	}
+
+	// Only messages of the others the user has not marked yet
+	idRange := bson.M{"$lte": upToID}
+	if !afterID.IsZero() {
+		idRange["$gt"] = afterID
+	}
+	filter := bson.M{
+		"chatId":          objID,
+		"_id":             idRange,
+		"from":            bson.M{"$ne": userID},
+		"expiresAt":       unexpired(),
+		field + ".userId": bson.M{"$ne": userID},
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "from": 1}).
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(models.MaxReceiptMessages)
+	cursor, err := r.messageDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	marked := []models.Message{}
+	if err := cursor.All(ctx, &marked); err != nil {
+		return nil, err
+	}
+	if len(marked) == 0 {
+		return marked, nil
+	}
+	slices.Reverse(marked)
+	ids := make([]primitive.ObjectID, 0, len(marked))
+	for _, message := range marked {
+		ids = append(ids, message.ID)
+	}
+
+	receipt := models.Receipt{UserID: userID, At: time.Now()}
+	if status == models.ReceiptRead {
+		if err := r.pushReceipt(ctx, ids, userID, "deliveredTo", receipt); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.pushReceipt(ctx, ids, userID, field, receipt); err != nil {
+		return nil, err
+	}
+	return marked, nil
+}
+
+func (r *messageRepo) pushReceipt(ctx context.Context, ids []primitive.ObjectID, userID string, field string, receipt models.Receipt) error {
+	filter := bson.M{
+		"_id":             bson.M{"$in": ids},
+		field + ".userId": bson.M{"$ne": userID},
+	}
+	update := bson.M{
+		"$push": bson.M{field: receipt},
+	}
+	_, err := r.messageDb.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// EditMessage replaces the text and the mentions of the message and keeps the previous text in its edit history.
+// It fails with models.ErrMessageNotFound when the message changed since it was read.
+func (r *messageRepo) EditMessage(message models.Message, text string, mentions []models.Mention) (models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":     message.ID,
+		"message": message.Message,
+		"deleted": bson.M{"$ne": true},
+	}
+	set := bson.M{
+		"message":  text,
+		"editedAt": now,
+	}
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"editHistory": models.MessageEdit{Message: message.Message, EditedAt: now},
+		},
+	}
+	if len(mentions) > 0 {
+		set["mentions"] = mentions
+	} else {
+		update["$unset"] = bson.M{"mentions": ""}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	edited := models.Message{}
+	err := r.messageDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&edited)
+	if err == mongo.ErrNoDocuments {
+		return models.Message{}, models.ErrMessageNotFound
+	} else if err != nil {
+		return models.Message{}, err
+	}
+	return edited, nil
+}
+
+// DeleteMessageForEveryone turns the message into a tombstone, dropping its text and edit history.
+// A deleted reply no longer counts towards the replies of its thread.
+func (r *messageRepo) DeleteMessageForEveryone(message models.Message) (models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": message.ID, "deleted": bson.M{"$ne": true}}
+	update := bson.M{
+		"$set": bson.M{
+			"message":   "",
+			"deleted":   true,
+			"deletedAt": time.Now(),
+		},
+		"$unset": bson.M{
+			"editHistory": "",
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	deleted := models.Message{}
+	err := r.messageDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		return models.Message{}, models.ErrMessageNotFound
+	} else if err != nil {
+		return models.Message{}, err
+	}
+
+	if deleted.ThreadID != nil {
+		_, err = r.messageDb.UpdateOne(ctx, bson.M{"_id": *deleted.ThreadID}, bson.M{"$inc": bson.M{"replyCount": -1}})
+		if err != nil {
+			return models.Message{}, err
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteMessageForUser hides the message from the user only
+func (r *messageRepo) DeleteMessageForUser(message models.Message, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": message.ID}
+	update := bson.M{
+		"$addToSet": bson.M{"deletedFor": userID},
+	}
+	_, err := r.messageDb.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// AddReaction adds the user to the users who reacted to the message with the emoji
+func (r *messageRepo) AddReaction(message models.Message, userID string, emoji string) (models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": message.ID, "deleted": bson.M{"$ne": true}}
+	update := bson.M{
+		"$addToSet": bson.M{"reactions." + emoji: userID},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	updated := models.Message{}
+	err := r.messageDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return models.Message{}, models.ErrMessageNotFound
+	} else if err != nil {
+		return models.Message{}, err
+	}
+	return updated, nil
+}
+
+// RemoveReaction removes the user from the users who reacted to the message with the emoji,
+// the emoji is dropped once nobody reacts with it anymore
+func (r *messageRepo) RemoveReaction(message models.Message, userID string, emoji string) (models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	field := "reactions." + emoji
+	_, err := r.messageDb.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$pull": bson.M{field: userID}})
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	filter := bson.M{"_id": message.ID, field: bson.M{"$size": 0}}
+	update := bson.M{"$unset": bson.M{field: ""}}
+	_, err = r.messageDb.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	updated := models.Message{}
+	err = r.messageDb.FindOne(ctx, bson.M{"_id": message.ID}).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return models.Message{}, models.ErrMessageNotFound
+	} else if err != nil {
+		return models.Message{}, err
+	}
+	return updated, nil
+}
+
+func (r *messageRepo) DeleteChatMessages(chatID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	_, err = r.messageDb.DeleteMany(ctx, bson.M{"chatId": objID})
+	return err
+}
+
+// GetExpiredMessages returns up to limit messages of chats with disappearing messages
+// that expired at now, the first to expire first
+func (r *messageRepo) GetExpiredMessages(now time.Time, limit int) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"expiresAt": bson.M{"$lte": now}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.messageDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// DeleteMessages removes the messages for good, the replies among them that were
+// not deleted yet stop counting towards the replies of their thread
+func (r *messageRepo) DeleteMessages(messages []models.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messageIDs := make([]primitive.ObjectID, 0, len(messages))
+	replies := map[primitive.ObjectID]int{}
+	for _, message := range messages {
+		messageIDs = append(messageIDs, message.ID)
+		if message.ThreadID != nil && !message.Deleted {
+			replies[*message.ThreadID]++
+		}
+	}
+	if _, err := r.messageDb.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": messageIDs}}); err != nil {
+		return err
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+
+	updates := make([]mongo.WriteModel, 0, len(replies))
+	for threadID, count := range replies {
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": threadID}).
+			SetUpdate(bson.M{"$inc": bson.M{"replyCount": -count}}))
+	}
+	_, err := r.messageDb.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false))
+	return err
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type readMarkerRepo struct {
+	readMarkerDb *mongo.Collection
+}
+
+type ReadMarkerRepo interface {
+	// Setup
+	CreateIndexes() error
+
+	// Get
+	GetReadMarker(chatID string, userID string) (primitive.ObjectID, error)
+	GetReadMarkers(userID string, chatIDs []string) (map[string]primitive.ObjectID, error)
+
+	// Update
+	SetReadMarker(chatID string, userID string, messageID primitive.ObjectID) (primitive.ObjectID, error)
+
+	// Delete
+	DeleteChatReadMarkers(chatID string) error
+}
+
+func NewReadMarkerRepo(readMarkerDb *mongo.Collection) ReadMarkerRepo {
+	return &readMarkerRepo{
+		readMarkerDb: readMarkerDb,
+	}
+}
+
+// CreateIndexes keeps a single marker per user and chat
+func (r *readMarkerRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.readMarkerDb.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "chatId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "chatId", Value: 1}}},
+	})
+	return err
+}
+
+// GetReadMarker returns the last message the user read in the chat, a zero ID when the user never read it
+func (r *readMarkerRepo) GetReadMarker(chatID string, userID string) (primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return primitive.NilObjectID, models.ErrChatNotFound
+	}
+
+	marker := models.ReadMarker{}
+	err = r.readMarkerDb.FindOne(ctx, bson.M{"chatId": objID, "userId": userID}).Decode(&marker)
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, nil
+	} else if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return marker.MessageID, nil
+}
+
+// GetReadMarkers returns the markers of the user by chat ID, chats the user never read are left out
+func (r *readMarkerRepo) GetReadMarkers(userID string, chatIDs []string) (map[string]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objIDs := make([]primitive.ObjectID, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		if objID, err := primitive.ObjectIDFromHex(chatID); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	filter := bson.M{"userId": userID, "chatId": bson.M{"$in": objIDs}}
+	cursor, err := r.readMarkerDb.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	markers := []models.ReadMarker{}
+	if err := cursor.All(ctx, &markers); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]primitive.ObjectID, len(markers))
+	for _, marker := range markers {
+		result[marker.ChatID.Hex()] = marker.MessageID
+	}
+	return result, nil
+}
+
+// SetReadMarker moves the marker of the user forward to the message, it never moves back.
+// It returns the marker after the update.
+func (r *readMarkerRepo) SetReadMarker(chatID string, userID string, messageID primitive.ObjectID) (primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return primitive.NilObjectID, models.ErrChatNotFound
+	}
+
+	filter := bson.M{"chatId": objID, "userId": userID}
+	update := bson.M{
+		"$max": bson.M{"messageId": messageID},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	marker := models.ReadMarker{}
+	if err := r.readMarkerDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&marker); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return marker.MessageID, nil
+}
+
+func (r *readMarkerRepo) DeleteChatReadMarkers(chatID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.ErrChatNotFound
+	}
+
+	_, err = r.readMarkerDb.DeleteMany(ctx, bson.M{"chatId": objID})
+	return err
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type scheduledMessageRepo struct {
+	scheduledDb *mongo.Collection
+}
+
+type ScheduledMessageRepo interface {
+	// Setup
+	CreateIndexes() error
+
+	// Get
+	GetScheduledMessage(id string, userID string) (models.ScheduledMessage, error)
+	GetScheduledMessages(userID string, chatID string) ([]models.ScheduledMessage, error)
+
+	// Create
+	InsertScheduledMessage(message models.ScheduledMessage) error
+
+	// Manage
+	ClaimDueMessage(now time.Time, lease time.Duration) (models.ScheduledMessage, bool, error)
+	FailScheduledMessage(id primitive.ObjectID, reason string, retryAt *time.Time) error
+
+	// Update
+	UpdateScheduledMessage(message models.ScheduledMessage) (models.ScheduledMessage, error)
+
+	// Delete
+	DeleteScheduledMessage(id string, userID string) error
+	CompleteScheduledMessage(id primitive.ObjectID) error
+}
+
+func NewScheduledMessageRepo(scheduledDb *mongo.Collection) ScheduledMessageRepo {
+	return &scheduledMessageRepo{
+		scheduledDb: scheduledDb,
+	}
+}
+
+// CreateIndexes makes sure the dispatcher finds due messages and users list theirs without a scan
+func (r *scheduledMessageRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.scheduledDb.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "sendAt", Value: 1}}},
+		{Keys: bson.D{{Key: "from", Value: 1}, {Key: "chatId", Value: 1}, {Key: "sendAt", Value: 1}}},
+	})
+	return err
+}
+
+func (r *scheduledMessageRepo) GetScheduledMessage(id string, userID string) (models.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.ScheduledMessage{}, models.ErrScheduledNotFound
+	}
+
+	message := models.ScheduledMessage{}
+	filter := bson.M{"_id": objID, "from": userID}
+	err = r.scheduledDb.FindOne(ctx, filter).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return models.ScheduledMessage{}, models.ErrScheduledNotFound
+	} else if err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	return message, nil
+}
+
+// GetScheduledMessages returns the messages the user scheduled and that were not sent yet,
+// in the order they are due. chatID is optional.
+func (r *scheduledMessageRepo) GetScheduledMessages(userID string, chatID string) ([]models.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"from": userID}
+	if chatID != "" {
+		objID, err := primitive.ObjectIDFromHex(chatID)
+		if err != nil {
+			return nil, models.ErrChatNotFound
+		}
+		filter["chatId"] = objID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "sendAt", Value: 1}})
+	cursor, err := r.scheduledDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []models.ScheduledMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *scheduledMessageRepo) InsertScheduledMessage(message models.ScheduledMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.scheduledDb.InsertOne(ctx, message)
+	return err
+}
+
+// ClaimDueMessage hands a due message to a single instance. Messages claimed by an
+// instance that did not finish sending them within the lease are claimed again, which
+// counts as a failed attempt. The first claim picks the ID the message is stored with.
+func (r *scheduledMessageRepo) ClaimDueMessage(now time.Time, lease time.Duration) (models.ScheduledMessage, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": models.ScheduledPending, "sendAt": bson.M{"$lte": now}},
+		bson.M{"status": models.ScheduledSending, "claimedAt": bson.M{"$lt": now.Add(-lease)}},
+	}}
+	expired := bson.M{"$eq": bson.A{"$status", models.ScheduledSending}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"attempts": bson.M{"$cond": bson.A{
+			expired,
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$attempts", 0}}, 1}},
+			bson.M{"$ifNull": bson.A{"$attempts", 0}},
+		}},
+		"error": bson.M{"$cond": bson.A{
+			expired,
+			"the instance sending it stopped responding",
+			bson.M{"$ifNull": bson.A{"$error", ""}},
+		}},
+		"messageId": bson.M{"$ifNull": bson.A{"$messageId", primitive.NewObjectID()}},
+		"status":    models.ScheduledSending,
+		"claimedAt": now,
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "sendAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	message := models.ScheduledMessage{}
+	err := r.scheduledDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return models.ScheduledMessage{}, false, nil
+	} else if err != nil {
+		return models.ScheduledMessage{}, false, err
+	}
+	return message, true, nil
+}
+
+// FailScheduledMessage records a failed attempt to send a claimed message, it is pending
+// again until retryAt when that is set and failed for good otherwise
+func (r *scheduledMessageRepo) FailScheduledMessage(id primitive.ObjectID, reason string, retryAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"status":    models.ScheduledFailed,
+		"error":     reason,
+		"updatedAt": time.Now(),
+	}
+	if retryAt != nil {
+		set["status"] = models.ScheduledPending
+		set["sendAt"] = *retryAt
+	}
+	update := bson.M{
+		"$set":   set,
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"claimedAt": ""},
+	}
+	_, err := r.scheduledDb.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// UpdateScheduledMessage changes the text and the time of a message that is still pending
+func (r *scheduledMessageRepo) UpdateScheduledMessage(message models.ScheduledMessage) (models.ScheduledMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": message.ID, "from": message.From, "status": models.ScheduledPending}
+	update := bson.M{"$set": bson.M{
+		"message":   message.Message,
+		"sendAt":    message.SendAt,
+		"updatedAt": time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	updated := models.ScheduledMessage{}
+	err := r.scheduledDb.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return models.ScheduledMessage{}, models.ErrScheduledNotFound
+	} else if err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	return updated, nil
+}
+
+// DeleteScheduledMessage cancels a pending message, or dismisses one that failed
+func (r *scheduledMessageRepo) DeleteScheduledMessage(id string, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.ErrScheduledNotFound
+	}
+
+	filter := bson.M{
+		"_id":    objID,
+		"from":   userID,
+		"status": bson.M{"$in": bson.A{models.ScheduledPending, models.ScheduledFailed}},
+	}
+	result, err := r.scheduledDb.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrScheduledNotFound
+	}
+	return nil
+}
+
+// CompleteScheduledMessage removes a message once it was sent, it lives on in the chat
+func (r *scheduledMessageRepo) CompleteScheduledMessage(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.scheduledDb.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -22,10 +22,13 @@ type UserRepo interface {
 	GetUsersByIDs(userIDs []string) ([]models.User, error)
 	GetUserByEmail(email string) (models.User, error)
 	GetUserByUsername(username string) (models.User, error)
+	GetUsersByUsernames(userIDs []string, usernames []string) ([]models.User, error)
 
 	CreateUser(user models.User) error
 
 	AddChatToUser(userID string, chatID string) error
+	RemoveChatFromUser(userID string, chatID string) error
+	RemoveChatFromUsers(userIDs []string, chatID string) error
 	AddPostToUser(userID string, postID string) error
 
 	UpdateUser(user models.User) error
@@ -63,13 +66,15 @@ func (r *userRepo) GetUserByID(id string) (models.User, error) {
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Fatalf("Invalid ObjectID: %v", err)
+		return models.User{}, models.ErrUserNotFound
 	}
 
 	u := models.User{}
 	filter := bson.M{"_id": objID}
 	err = r.database.FindOne(ctx, filter).Decode(&u)
-	if err != nil {
+	if err == mongo.ErrNoDocuments {
+		return models.User{}, models.ErrUserNotFound
+	} else if err != nil {
 		return models.User{}, err
 	}
 	return u, nil
@@ -102,6 +107,34 @@ func (r *userRepo) GetUsersByIDs(userIDs []string) ([]models.User, error) {
 	return users, nil
 }
 
+// GetUsersByUsernames returns the users among userIDs that have one of the usernames
+func (r *userRepo) GetUsersByUsernames(userIDs []string, usernames []string) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectIDs := []primitive.ObjectID{}
+	for _, id := range userIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objectIDs = append(objectIDs, objID)
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": objectIDs}, "username": bson.M{"$in": usernames}}
+	cursor, err := r.database.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepo) GetUserByEmail(email string) (models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -195,6 +228,46 @@ func (r *userRepo) AddChatToUser(userID string, chatID string) error {
 	return nil
 }
 
+// RemoveChatFromUser is the inverse of AddChatToUser, removing a chat the user does not have is not an error
+func (r *userRepo) RemoveChatFromUser(userID string, chatID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %v", err)
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$pull": bson.M{"chats": chatID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	_, err = r.database.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RemoveChatFromUsers removes a deleted chat from the chats of all its members at once
+func (r *userRepo) RemoveChatFromUsers(userIDs []string, chatID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objIDs := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if objID, err := primitive.ObjectIDFromHex(userID); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": objIDs}}
+	update := bson.M{
+		"$pull": bson.M{"chats": chatID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	_, err := r.database.UpdateMany(ctx, filter, update)
+	return err
+}
+
 func (r *userRepo) AddPostToUser(userID string, postID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
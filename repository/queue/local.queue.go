@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+const localQueueSize = 256
+
+// localRetryDelay is how long a message whose handler failed waits before it is handled again
+var localRetryDelay = 5 * time.Second
+
+// ErrQueueFull is returned by the in-process queue instead of blocking the sender
+var ErrQueueFull = errors.New("queue is full")
+
+// localQueueRepo keeps the queues in process, the whole AI round-trip can run
+// without any cloud service in local development and tests
+type localQueueRepo struct {
+	queues map[string]chan []byte
+
+	sync.Mutex
+}
+
+func NewLocalQueueRepo() QueueRepo {
+	return &localQueueRepo{
+		queues: make(map[string]chan []byte),
+	}
+}
+
+// Send never blocks, a queue nobody receives from fills up and then rejects messages
+func (r *localQueueRepo) Send(queue string, body []byte) error {
+	select {
+	case r.queue(queue) <- body:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (r *localQueueRepo) Receive(queue string, handler QueueHandler) {
+	messages := r.queue(queue)
+	for body := range messages {
+		if err := handler(body); err != nil {
+			log.Printf("Failed to handle message of queue %s, retrying: %v", queue, err)
+			r.retry(queue, body)
+		}
+	}
+}
+
+// retry puts the message back into the queue after localRetryDelay, waiting again while the queue is full
+func (r *localQueueRepo) retry(queue string, body []byte) {
+	time.AfterFunc(localRetryDelay, func() {
+		if err := r.Send(queue, body); err != nil {
+			r.retry(queue, body)
+		}
+	})
+}
+
+// queue returns the channel of the queue, creating it on first use
+func (r *localQueueRepo) queue(name string) chan []byte {
+	r.Lock()
+	defer r.Unlock()
+
+	messages, ok := r.queues[name]
+	if !ok {
+		messages = make(chan []byte, localQueueSize)
+		r.queues[name] = messages
+	}
+	return messages
+}
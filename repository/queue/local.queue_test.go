@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLocalQueue(t *testing.T) {
+	localRetryDelay = 10 * time.Millisecond
+	testTransport(t, NewLocalQueueRepo(), "local")
+}
+
+func TestLocalQueueSendDoesNotBlock(t *testing.T) {
+	repo := NewLocalQueueRepo()
+	for i := 0; i < localQueueSize; i++ {
+		if err := repo.Send(PublisherQueue, []byte("request")); err != nil {
+			t.Fatalf("Send %d = %v", i, err)
+		}
+	}
+
+	sent := make(chan error, 1)
+	go func() { sent <- repo.Send(PublisherQueue, []byte("one too many")) }()
+	select {
+	case err := <-sent:
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("Send on a full queue = %v, want ErrQueueFull", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send blocked on a full queue")
+	}
+}
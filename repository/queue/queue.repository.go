@@ -0,0 +1,21 @@
+package queue
+
+// Queues of the Meeyok AI pipeline, the backend publishes requests and receives the replies
+const (
+	PublisherQueue = "publisher"
+	ReceiverQueue  = "receiver"
+	// DeadLetterQueue holds the messages that could not be handled, see models.DeadLetter
+	DeadLetterQueue = "deadletter"
+)
+
+// QueueHandler handles a message received from a queue, returning nil acknowledges it.
+// A message whose handler fails stays in the queue and is delivered again later.
+type QueueHandler func(body []byte) error
+
+// QueueRepo carries messages between the backend and the AI worker,
+// whatever transport is behind it. Each message is handled by a single receiver.
+type QueueRepo interface {
+	Send(queue string, body []byte) error
+	// Receive hands the messages of the queue to the handler, it blocks for as long as the server runs
+	Receive(queue string, handler QueueHandler)
+}
@@ -2,146 +2,167 @@ package queueReceiver
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
-	"os"
-	"sync"
 	"time"
 
-	"github.com/Meeyok-Chat/backend/configs"
 	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/repository/queue"
 	Websocket "github.com/Meeyok-Chat/backend/services/websocket"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type QueueReceiver struct {
-	sqsSvc *sqs.SQS
+// deliveryBackoff is the delay before the first retry of a reply, it doubles on every retry
+const deliveryBackoff = 500 * time.Millisecond
 
-	sync.RWMutex
+type QueueReceiver struct {
+	queueRepo      queue.QueueRepo
+	aiRequestRepo  database.AIRequestRepo
+	deadLetterRepo database.DeadLetterRepo
 
 	managerService Websocket.ManagerService
 }
 
-const (
-	ReceiverQueue = "AWS_SQS_RECEIVER_URL"
-	// DLQueue       = "AWS_SQS_DEADLETTERQUEUE_URL"
-)
+func NewConsumerManager(queueRepo queue.QueueRepo, aiRequestRepo database.AIRequestRepo, deadLetterRepo database.DeadLetterRepo, managerService Websocket.ManagerService) *QueueReceiver {
+	cm := &QueueReceiver{
+		queueRepo:      queueRepo,
+		aiRequestRepo:  aiRequestRepo,
+		deadLetterRepo: deadLetterRepo,
+		managerService: managerService,
+	}
+	return cm
+}
 
-// AWS SQS
-func ConnectSQS() *sqs.SQS {
-	accessKeyID := configs.GetEnv("AWS_ACCESS_KEY_ID")
-	secretAccessKey := configs.GetEnv("AWS_SECRET_ACCESS_KEY")
+// ReadResult receives the replies of the AI worker for as long as the server runs
+func (cm *QueueReceiver) ReadResult() {
+	cm.queueRepo.Receive(queue.ReceiverQueue, cm.Read)
+}
 
-	sess, _ := session.NewSession(&aws.Config{
-		Region:      aws.String(configs.GetEnv("AWS_REGION")),
-		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
-		MaxRetries:  aws.Int(5),
-	})
+// ReadDLQ stores the dead-lettered messages for as long as the server runs, so admins can inspect and replay them
+func (cm *QueueReceiver) ReadDLQ() {
+	cm.queueRepo.Receive(queue.DeadLetterQueue, cm.storeDeadLetter)
+}
 
-	sqsSvc := sqs.New(sess)
+// Read handles a single reply of the AI worker. A reply that cannot be parsed or delivered
+// is dead-lettered, it is only left in the queue when dead-lettering it fails too.
+func (cm *QueueReceiver) Read(body []byte) error {
+	var parsedEvent models.Event
+	if err := json.Unmarshal(body, &parsedEvent); err != nil {
+		return cm.deadLetter(body, "", fmt.Errorf("failed to parse event: %v", err))
+	}
+	var payload models.QueueReceiverPayload
+	if err := json.Unmarshal(parsedEvent.Payload, &payload); err != nil {
+		return cm.deadLetter(body, "", fmt.Errorf("failed to parse payload: %v", err))
+	}
 
-	return sqsSvc
-}
+	// A delta that could not be buffered is left for redelivery, the done part waits for it
+	if payload.Type == models.AIReplyDelta {
+		return cm.SendMessageToClient(payload)
+	}
 
-func NewConsumerManager(managerService Websocket.ManagerService) *QueueReceiver {
-	cm := &QueueReceiver{
-		sqsSvc:         ConnectSQS(),
-		managerService: managerService,
+	var err error
+	backoff := deliveryBackoff
+	for attempt := 1; attempt <= models.AIReplyMaxAttempts; attempt++ {
+		if err = cm.SendMessageToClient(payload); err == nil {
+			return nil
+		}
+		log.Printf("Failed to deliver reply %s (attempt %d/%d): %v", payload.CorrelationID, attempt, models.AIReplyMaxAttempts, err)
+		if attempt < models.AIReplyMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
 	}
-	return cm
+	if dlqErr := cm.deadLetter(body, payload.CorrelationID, err); dlqErr != nil {
+		return dlqErr
+	}
+	if payload.CorrelationID != "" {
+		cm.fail(models.AIRequestID(payload.CorrelationID), payload.From, err)
+	}
+	return nil
 }
 
-func (cm *QueueReceiver) SQSSendMessage(message []byte, QueueUrl string) {
-	_, err := cm.sqsSvc.SendMessage(&sqs.SendMessageInput{
-		MessageBody: aws.String(string(message)),
-		QueueUrl:    aws.String(QueueUrl),
-	})
+// fail marks the request failed and lets the chat know Meeyok AI could not answer it,
+// unless the request was answered or given up on already
+func (cm *QueueReceiver) fail(requestID string, chatID string, reason error) {
+	failed, err := cm.aiRequestRepo.SetAIRequestStatus(requestID, models.AIRequestFailed, reason.Error(),
+		models.AIRequestPending)
 	if err != nil {
-		log.Println(err)
+		log.Printf("Failed to mark AI request %s failed: %v", requestID, err)
 		return
 	}
-	log.Println("Sending to publisher queue message successfully")
+	if !failed {
+		return
+	}
+	if err := cm.managerService.SendBotMessage(chatID, models.AIFailureMessage); err != nil {
+		log.Printf("Failed to send failure of AI request %s: %v", requestID, err)
+	}
 }
 
-func (cm *QueueReceiver) ReadResult() {
-	for {
-		cm.Read(ReceiverQueue)
+// SendMessageToClient delivers a reply, or a part of a streamed reply, to the members of the chat.
+// Replies reach members connected to any instance through the broadcast bus.
+func (cm *QueueReceiver) SendMessageToClient(payload models.QueueReceiverPayload) error {
+	switch {
+	case payload.Type == models.AIReplyDelta:
+		return cm.managerService.SendAIReplyDelta(payload.From, payload.CorrelationID, payload.Seq, payload.Delta)
+	case payload.CorrelationID != "":
+		log.Println("Bot replied to client")
+		return cm.managerService.SendAIReplyDone(payload.From, payload.CorrelationID, payload.Message, payload.Deltas)
+	default:
+		log.Println("Bot replied to client")
+		return cm.managerService.SendBotMessage(payload.From, payload.Message)
 	}
 }
 
-// func (cm *QueueReceiver) ReadDLQ() {
-// 	for {
-// 		cm.Read(DLQueue)
-// 	}
-// }
-
-func (cm *QueueReceiver) Read(queueUrl string) {
-	chnMessages := make(chan *sqs.Message, 2)
-	go cm.PollMessages(chnMessages, queueUrl)
-
-	for message := range chnMessages {
-		var parsedEvent models.Event
-		if err := json.Unmarshal([]byte(*message.Body), &parsedEvent); err != nil {
-			log.Println(err)
-			continue
-		}
-		var payload models.QueueReceiverPayload
-		if err := json.Unmarshal(parsedEvent.Payload, &payload); err != nil {
-			log.Println(err)
-			continue
+// deadLetter moves a reply that could not be handled to the dead-letter queue
+func (cm *QueueReceiver) deadLetter(body []byte, correlationID string, reason error) error {
+	log.Printf("Dead-lettering reply %s: %v", correlationID, reason)
+	envelope, err := json.Marshal(models.DeadLetter{
+		Queue:         queue.ReceiverQueue,
+		Body:          string(body),
+		Error:         reason.Error(),
+		CorrelationID: correlationID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := cm.queueRepo.Send(queue.DeadLetterQueue, envelope); err != nil {
+		// Without a dead-letter queue the reply is kept for admins right away
+		log.Printf("Failed to send reply %s to the dead-letter queue, storing it: %v", correlationID, err)
+		if err := cm.storeDeadLetter(envelope); err != nil {
+			return fmt.Errorf("failed to dead-letter reply: %v", err)
 		}
-		log.Println()
-		var messageData models.SendMessageEvent
-		messageData.ChatID = payload.From
-		messageData.Message = payload.Message
-		messageData.From = "Meeyok AI"
-		messageData.CreatedAt = time.Now()
-
-		parsedEvent.Payload, _ = json.Marshal(messageData)
-
-		cm.SendMessageToClient(parsedEvent, queueUrl)
-		cm.SQSDeleteMessage(message, configs.GetEnv(queueUrl))
 	}
+	return nil
 }
 
-func (cm *QueueReceiver) PollMessages(chn chan<- *sqs.Message, queueUrl string) {
-	sqsSvc := ConnectSQS()
-	for {
-		output, err := sqsSvc.ReceiveMessage(&sqs.ReceiveMessageInput{
-			QueueUrl:            aws.String(os.Getenv(queueUrl)),
-			MaxNumberOfMessages: aws.Int64(2),
-			WaitTimeSeconds:     aws.Int64(15),
-		})
-
-		if err != nil {
-			log.Println(err)
-		}
-
-		for _, message := range output.Messages {
-			chn <- message
+// storeDeadLetter keeps a message of the dead-letter queue. Messages moved there by the
+// transport itself, like an SQS redrive policy, are not wrapped and came from the receiver queue.
+func (cm *QueueReceiver) storeDeadLetter(body []byte) error {
+	var deadLetter models.DeadLetter
+	if err := json.Unmarshal(body, &deadLetter); err != nil || deadLetter.Queue == "" {
+		deadLetter = models.DeadLetter{
+			Queue:         queue.ReceiverQueue,
+			Body:          string(body),
+			Error:         "redelivered too many times",
+			CorrelationID: correlationIDOf(body),
 		}
 	}
+	deadLetter.ID = primitive.NewObjectID()
+	deadLetter.CreatedAt = time.Now()
+	deadLetter.ReplayedAt = nil
+	return cm.deadLetterRepo.InsertDeadLetter(deadLetter)
 }
 
-func (cm *QueueReceiver) SendMessageToClient(event models.Event, queueUrl string) {
-	clients := cm.managerService.GetClients()
-
-	if clients == nil {
-		return
+// correlationIDOf makes a best effort to find the correlation ID of a raw reply
+func correlationIDOf(body []byte) string {
+	var parsedEvent models.Event
+	if err := json.Unmarshal(body, &parsedEvent); err != nil {
+		return ""
 	}
-
-	log.Println("Bot replied to client")
-	if queueUrl == ReceiverQueue {
-		cm.managerService.SendMessageHandler(event, &models.Client{})
+	var payload models.QueueReceiverPayload
+	if err := json.Unmarshal(parsedEvent.Payload, &payload); err != nil {
+		return ""
 	}
-	clients = nil
-}
-
-func (cm *QueueReceiver) SQSDeleteMessage(msg *sqs.Message, queueUrl string) {
-	cm.sqsSvc.DeleteMessage(&sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(queueUrl),
-		ReceiptHandle: msg.ReceiptHandle,
-	})
+	return payload.CorrelationID
 }
@@ -0,0 +1,63 @@
+package queueWorker
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/queue"
+)
+
+// StubWorker stands in for the AI worker when the queues are in process, it answers
+// every request with a canned reply so the whole round-trip runs in local development
+type StubWorker struct {
+	queueRepo queue.QueueRepo
+}
+
+func NewStubWorker(queueRepo queue.QueueRepo) *StubWorker {
+	return &StubWorker{
+		queueRepo: queueRepo,
+	}
+}
+
+// Run answers the requests for as long as the server runs
+func (w *StubWorker) Run() {
+	w.queueRepo.Receive(queue.PublisherQueue, w.Reply)
+}
+
+// Reply answers a single request, a request that cannot be parsed is dropped
+func (w *StubWorker) Reply(body []byte) error {
+	var parsedEvent models.Event
+	if err := json.Unmarshal(body, &parsedEvent); err != nil {
+		log.Printf("Stub worker dropped a request: %v", err)
+		return nil
+	}
+	var request models.QueuePublisherPayload
+	if err := json.Unmarshal(parsedEvent.Payload, &request); err != nil {
+		log.Printf("Stub worker dropped a request: %v", err)
+		return nil
+	}
+
+	payload, err := json.Marshal(models.QueueReceiverPayload{
+		From:          request.From,
+		Message:       StubReply(request.Prompt),
+		CorrelationID: request.CorrelationID,
+		Type:          models.AIReplyDone,
+	})
+	if err != nil {
+		return err
+	}
+	reply, err := json.Marshal(models.Event{Type: models.EventAIReplyDone, Payload: payload})
+	if err != nil {
+		return err
+	}
+	return w.queueRepo.Send(queue.ReceiverQueue, reply)
+}
+
+// StubReply is what the stub worker answers to the prompt
+func StubReply(prompt string) string {
+	if prompt == "" {
+		return "Meeyok AI is not connected, this is a stub reply."
+	}
+	return "Meeyok AI is not connected, this is a stub reply to: " + prompt
+}
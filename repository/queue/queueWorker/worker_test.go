@@ -0,0 +1,76 @@
+package queueWorker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/queue"
+)
+
+func TestStubWorkerRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.QueuePublisherPayload
+	}{
+		{
+			name:    "prompt",
+			request: models.QueuePublisherPayload{From: "chat-1", CorrelationID: "req-1", Prompt: "what's up"},
+		},
+		{
+			name:    "empty prompt",
+			request: models.QueuePublisherPayload{From: "chat-2", CorrelationID: "req-2"},
+		},
+	}
+
+	queueRepo := queue.NewLocalQueueRepo()
+	go NewStubWorker(queueRepo).Run()
+	replies := make(chan models.QueueReceiverPayload, len(tests))
+	go queueRepo.Receive(queue.ReceiverQueue, func(body []byte) error {
+		var event models.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			return err
+		}
+		var reply models.QueueReceiverPayload
+		if err := json.Unmarshal(event.Payload, &reply); err != nil {
+			return err
+		}
+		replies <- reply
+		return nil
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.request)
+			request, _ := json.Marshal(models.Event{Type: models.EventSendMessageToMeeyok, Payload: payload})
+			if err := queueRepo.Send(queue.PublisherQueue, request); err != nil {
+				t.Fatalf("Send = %v", err)
+			}
+
+			select {
+			case reply := <-replies:
+				want := models.QueueReceiverPayload{
+					From:          tt.request.From,
+					Message:       StubReply(tt.request.Prompt),
+					CorrelationID: tt.request.CorrelationID,
+					Type:          models.AIReplyDone,
+				}
+				if reply != want {
+					t.Errorf("reply = %+v, want %+v", reply, want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no reply from the stub worker")
+			}
+		})
+	}
+}
+
+func TestStubWorkerDropsMalformedRequests(t *testing.T) {
+	tests := []string{"not json", `{"type":"send_message_to_meeyok","payload":"not an object"}`}
+	for _, body := range tests {
+		if err := NewStubWorker(queue.NewLocalQueueRepo()).Reply([]byte(body)); err != nil {
+			t.Errorf("Reply(%q) = %v, want the request dropped", body, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiveTimeout bounds how long the tests wait for a message to come through
+const receiveTimeout = 30 * time.Second
+
+// collect receives the messages of the queue until want of them arrived, a failing
+// handler rejects the bodies in failFirst the first time they come in
+func collect(t *testing.T, repo QueueRepo, queue string, want int, failFirst map[string]bool) []string {
+	t.Helper()
+
+	var mu sync.Mutex
+	received := []string{}
+	failed := map[string]bool{}
+	done := make(chan struct{})
+	go repo.Receive(queue, func(body []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failFirst[string(body)] && !failed[string(body)] {
+			failed[string(body)] = true
+			return errors.New("handler failed")
+		}
+		received = append(received, string(body))
+		if len(received) == want {
+			close(done)
+		}
+		return nil
+	})
+
+	select {
+	case <-done:
+	case <-time.After(receiveTimeout):
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("received %d of %d messages: %v", len(received), want, received)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return received
+}
+
+// testTransport runs the contract every transport has to follow against repo
+func testTransport(t *testing.T, repo QueueRepo, queue string) {
+	tests := []struct {
+		name      string
+		bodies    []string
+		failFirst map[string]bool
+	}{
+		{name: "single message", bodies: []string{"one"}},
+		{name: "in order", bodies: []string{"a", "b", "c", "d"}},
+		{name: "failed message is delivered again", bodies: []string{"x", "y"}, failFirst: map[string]bool{"x": true}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := fmt.Sprintf("%s-%d-%d", queue, time.Now().UnixNano(), i)
+			for _, body := range tt.bodies {
+				if err := repo.Send(name, []byte(body)); err != nil {
+					t.Fatalf("Send(%q) = %v", body, err)
+				}
+			}
+
+			received := collect(t, repo, name, len(tt.bodies), tt.failFirst)
+			for _, body := range tt.bodies {
+				found := false
+				for _, got := range received {
+					found = found || got == body
+				}
+				if !found {
+					t.Errorf("message %q was not received, got %v", body, received)
+				}
+			}
+			if tt.failFirst == nil {
+				for i, body := range tt.bodies {
+					if received[i] != body {
+						t.Errorf("message %d = %q, want %q", i, received[i], body)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	QueueStreamPrefix = "meeyok:queue:"
+	// QueueConsumerGroup is shared by every instance, so each message is handled by one of them
+	QueueConsumerGroup = "meeyok-backend"
+	// redisQueueMaxLen keeps the streams from growing without bound
+	redisQueueMaxLen = 10000
+	redisReadBlock   = 15 * time.Second
+	redisReadCount   = 10
+)
+
+// redisClaimIdle is how long a message stays with a consumer that did not acknowledge it
+// before another consumer takes it over, like the visibility timeout of SQS
+var redisClaimIdle = time.Minute
+
+// redisQueueRepo sends and receives through Redis Streams with a consumer group
+type redisQueueRepo struct {
+	cache    *configs.RedisClient
+	consumer string
+}
+
+func NewRedisQueueRepo(cache *configs.RedisClient) QueueRepo {
+	host, _ := os.Hostname()
+	return &redisQueueRepo{
+		cache:    cache,
+		consumer: fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+func (r *redisQueueRepo) Send(queue string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.cache.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: QueueStreamPrefix + queue,
+		MaxLen: redisQueueMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"body": body},
+	}).Err()
+}
+
+func (r *redisQueueRepo) Receive(queue string, handler QueueHandler) {
+	stream := QueueStreamPrefix + queue
+	ctx := context.Background()
+
+	err := r.cache.Client.XGroupCreateMkStream(ctx, stream, QueueConsumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		log.Printf("Failed to create consumer group of queue %s: %v", queue, err)
+	}
+
+	for {
+		// Take over the messages of consumers that died before acknowledging them
+		claimed, _, err := r.cache.Client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    QueueConsumerGroup,
+			Consumer: r.consumer,
+			MinIdle:  redisClaimIdle,
+			Start:    "0",
+			Count:    redisReadCount,
+		}).Result()
+		if err != nil {
+			log.Printf("Failed to claim pending messages of queue %s: %v", queue, err)
+		}
+		r.handle(ctx, queue, claimed, handler)
+
+		streams, err := r.cache.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    QueueConsumerGroup,
+			Consumer: r.consumer,
+			Streams:  []string{stream, ">"},
+			Count:    redisReadCount,
+			Block:    redisReadBlock,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			log.Printf("Failed to receive from queue %s: %v", queue, err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, s := range streams {
+			r.handle(ctx, queue, s.Messages, handler)
+		}
+	}
+}
+
+// handle acknowledges every message its handler succeeded with, the others stay pending
+func (r *redisQueueRepo) handle(ctx context.Context, queue string, messages []redis.XMessage, handler QueueHandler) {
+	stream := QueueStreamPrefix + queue
+	for _, message := range messages {
+		body, _ := message.Values["body"].(string)
+		if err := handler([]byte(body)); err != nil {
+			log.Printf("Failed to handle message of queue %s, leaving it for redelivery: %v", queue, err)
+			continue
+		}
+		if err := r.cache.Client.XAck(ctx, stream, QueueConsumerGroup, message.ID).Err(); err != nil {
+			log.Printf("Failed to acknowledge message of queue %s: %v", queue, err)
+		}
+	}
+}
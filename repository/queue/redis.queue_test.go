@@ -0,0 +1,31 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+)
+
+// TestRedisQueue runs against the Redis of REDIS_URI, it is skipped when none is configured
+func TestRedisQueue(t *testing.T) {
+	if configs.GetEnv("REDIS_URI") == "" {
+		t.Skip("REDIS_URI is not set")
+	}
+	client, err := configs.NewRedisClient()
+	if err != nil {
+		t.Fatalf("failed to connect to Redis: %v", err)
+	}
+	// A failed message is taken over once it sat idle, and the read blocks for a while before that
+	redisClaimIdle = 100 * time.Millisecond
+
+	testTransport(t, NewRedisQueueRepo(client), "test")
+
+	t.Cleanup(func() {
+		keys, err := client.Client.Keys(context.Background(), QueueStreamPrefix+"test-*").Result()
+		if err == nil && len(keys) > 0 {
+			client.Client.Del(context.Background(), keys...)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// sqsQueueURLs are the environment variables holding the URL of every queue
+var sqsQueueURLs = map[string]string{
+	PublisherQueue: "AWS_SQS_PUBLISHER",
+	ReceiverQueue:  "AWS_SQS_RECEIVER_URL",
+	// The redrive policy of the receiver queue can point at it too
+	DeadLetterQueue: "AWS_SQS_DEADLETTERQUEUE_URL",
+}
+
+// sqsPollErrorDelay keeps a failing receive from spinning
+const sqsPollErrorDelay = 5 * time.Second
+
+// sqsQueueRepo sends and receives through AWS SQS, a message that is not
+// deleted comes back once its visibility timeout is over
+type sqsQueueRepo struct {
+	sqsSvc *sqs.SQS
+}
+
+func NewSQSQueueRepo() (QueueRepo, error) {
+	accessKeyID := configs.GetEnv("AWS_ACCESS_KEY_ID")
+	secretAccessKey := configs.GetEnv("AWS_SECRET_ACCESS_KEY")
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(configs.GetEnv("AWS_REGION")),
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		MaxRetries:  aws.Int(5),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sqsQueueRepo{
+		sqsSvc: sqs.New(sess),
+	}, nil
+}
+
+func (r *sqsQueueRepo) Send(queue string, body []byte) error {
+	_, err := r.sqsSvc.SendMessage(&sqs.SendMessageInput{
+		MessageBody: aws.String(string(body)),
+		QueueUrl:    aws.String(configs.GetEnv(sqsQueueURLs[queue])),
+	})
+	return err
+}
+
+func (r *sqsQueueRepo) Receive(queue string, handler QueueHandler) {
+	queueURL := aws.String(configs.GetEnv(sqsQueueURLs[queue]))
+	for {
+		output, err := r.sqsSvc.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:            queueURL,
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(15),
+		})
+		if err != nil {
+			log.Printf("Failed to receive from queue %s: %v", queue, err)
+			time.Sleep(sqsPollErrorDelay)
+			continue
+		}
+
+		for _, message := range output.Messages {
+			if err := handler([]byte(aws.StringValue(message.Body))); err != nil {
+				log.Printf("Failed to handle message of queue %s, leaving it for redelivery: %v", queue, err)
+				continue
+			}
+			_, err := r.sqsSvc.DeleteMessage(&sqs.DeleteMessageInput{
+				QueueUrl:      queueURL,
+				ReceiptHandle: message.ReceiptHandle,
+			})
+			if err != nil {
+				log.Printf("Failed to delete message of queue %s: %v", queue, err)
+			}
+		}
+	}
+}
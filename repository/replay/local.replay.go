@@ -0,0 +1,111 @@
+package replay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// sweepInterval is how often idle buffers are released
+const sweepInterval = time.Minute
+
+type userBuffer struct {
+	seq       uint64
+	events    []models.Event
+	updatedAt time.Time
+}
+
+// localReplayRepo keeps the buffers in memory, it is only correct on a single instance
+type localReplayRepo struct {
+	buffers map[string]*userBuffer
+
+	sync.Mutex
+}
+
+func NewLocalReplayRepo() ReplayRepo {
+	r := &localReplayRepo{
+		buffers: make(map[string]*userBuffer),
+	}
+	go r.sweep()
+	return r
+}
+
+func (r *localReplayRepo) Append(userIDs []string, event models.Event) (map[string]uint64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	sequences := make(map[string]uint64, len(userIDs))
+	for _, userID := range userIDs {
+		buffer, ok := r.buffers[userID]
+		if !ok {
+			buffer = &userBuffer{}
+			r.buffers[userID] = buffer
+		}
+		// Expired buffers keep counting so sequences never go backwards
+		if time.Since(buffer.updatedAt) > BufferTTL {
+			buffer.events = nil
+		}
+
+		buffer.seq++
+		stamped := event
+		stamped.Seq = buffer.seq
+		buffer.events = append(buffer.events, stamped)
+		if len(buffer.events) > BufferSize {
+			buffer.events = buffer.events[len(buffer.events)-BufferSize:]
+		}
+		buffer.updatedAt = time.Now()
+		sequences[userID] = buffer.seq
+	}
+	return sequences, nil
+}
+
+func (r *localReplayRepo) Since(userID string, lastSeq uint64) ([]models.Event, uint64, bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	buffer, ok := r.buffers[userID]
+	if !ok {
+		return []models.Event{}, 0, lastSeq == 0, nil
+	}
+	if lastSeq > buffer.seq {
+		return []models.Event{}, buffer.seq, false, nil
+	}
+
+	events := []models.Event{}
+	if time.Since(buffer.updatedAt) <= BufferTTL {
+		for _, event := range buffer.events {
+			if event.Seq > lastSeq {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, buffer.seq, isComplete(events, lastSeq, buffer.seq), nil
+}
+
+// sweep drops the events of idle users once they expire, and forgets users idle for longer than SeqTTL
+func (r *localReplayRepo) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.Lock()
+		for userID, buffer := range r.buffers {
+			idle := time.Since(buffer.updatedAt)
+			if idle > SeqTTL {
+				delete(r.buffers, userID)
+			} else if idle > BufferTTL {
+				buffer.events = nil
+			}
+		}
+		r.Unlock()
+	}
+}
+
+// isComplete reports whether events covers every sequence in (lastSeq, currentSeq]
+func isComplete(events []models.Event, lastSeq uint64, currentSeq uint64) bool {
+	if lastSeq == currentSeq {
+		return true
+	}
+	return len(events) > 0 && events[0].Seq == lastSeq+1
+}
@@ -0,0 +1,101 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/go-redis/redis/v8"
+)
+
+// redisReplayRepo shares the sequences and buffers between every instance,
+// so a client can resume on another instance than the one it dropped from
+type redisReplayRepo struct {
+	cache *configs.RedisClient
+}
+
+func NewRedisReplayRepo(cache *configs.RedisClient) ReplayRepo {
+	return &redisReplayRepo{
+		cache: cache,
+	}
+}
+
+func seqKey(userID string) string {
+	return fmt.Sprintf("meeyok:replay:%s:seq", userID)
+}
+
+func eventsKey(userID string) string {
+	return fmt.Sprintf("meeyok:replay:%s:events", userID)
+}
+
+// Append numbers and buffers the event for every user in two round trips, whatever their number
+func (r *redisReplayRepo) Append(userIDs []string, event models.Event) (map[string]uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipe := r.cache.Client.Pipeline()
+	incrs := make([]*redis.IntCmd, len(userIDs))
+	for i, userID := range userIDs {
+		incrs[i] = pipe.Incr(ctx, seqKey(userID))
+		pipe.Expire(ctx, seqKey(userID), SeqTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	sequences := make(map[string]uint64, len(userIDs))
+	pipe = r.cache.Client.Pipeline()
+	for i, userID := range userIDs {
+		stamped := event
+		stamped.Seq = uint64(incrs[i].Val())
+		data, err := json.Marshal(stamped)
+		if err != nil {
+			return nil, err
+		}
+		pipe.ZAdd(ctx, eventsKey(userID), &redis.Z{Score: float64(stamped.Seq), Member: data})
+		pipe.ZRemRangeByRank(ctx, eventsKey(userID), 0, -BufferSize-1)
+		pipe.Expire(ctx, eventsKey(userID), BufferTTL)
+		sequences[userID] = stamped.Seq
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return sequences, nil
+}
+
+func (r *redisReplayRepo) Since(userID string, lastSeq uint64) ([]models.Event, uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	currentSeq, err := r.cache.Client.Get(ctx, seqKey(userID)).Uint64()
+	if err == redis.Nil {
+		return []models.Event{}, 0, lastSeq == 0, nil
+	} else if err != nil {
+		return nil, 0, false, err
+	}
+	if lastSeq > currentSeq {
+		return []models.Event{}, currentSeq, false, nil
+	}
+
+	values, err := r.cache.Client.ZRangeByScore(ctx, eventsKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatUint(lastSeq, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	events := []models.Event{}
+	for _, value := range values {
+		var event models.Event
+		if err := json.Unmarshal([]byte(value), &event); err != nil {
+			return nil, 0, false, err
+		}
+		events = append(events, event)
+	}
+	return events, currentSeq, isComplete(events, lastSeq, currentSeq), nil
+}
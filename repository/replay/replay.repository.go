@@ -0,0 +1,26 @@
+package replay
+
+import (
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+const (
+	// BufferSize is how many events are kept per user for resuming
+	BufferSize = 200
+	// BufferTTL is how long a disconnected user's events are kept
+	BufferTTL = 10 * time.Minute
+	// SeqTTL outlives the buffer so a returning user keeps counting from where they were
+	SeqTTL = 7 * 24 * time.Hour
+)
+
+// ReplayRepo stamps outbound events with a per-user sequence number and keeps
+// a short buffer of them, so a reconnecting client can receive what it missed.
+type ReplayRepo interface {
+	// Append assigns the next sequence number of every user to the event and buffers it for each of them
+	Append(userIDs []string, event models.Event) (map[string]uint64, error)
+	// Since returns the buffered events after lastSeq, complete is false when
+	// some of them have already been dropped from the buffer
+	Since(userID string, lastSeq uint64) (events []models.Event, currentSeq uint64, complete bool, err error)
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// snippetLength is the number of characters of a snippet
+	snippetLength = 160
+	// snippetLead is the number of characters kept before the first match
+	snippetLead = 60
+)
+
+// searchTerms splits a query the way a Mongo text search reads it,
+// negated terms are left out as they never appear in a match
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		term := strings.ToLower(strings.Trim(field, `"'.,!?;:()`))
+		if term != "" && !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+type span struct {
+	start, end int
+}
+
+// highlight cuts a snippet around the first match of the terms out of the text,
+// escapes it and wraps every match in <mark> tags
+func highlight(text string, terms []string) string {
+	runes := []rune(text)
+	// Lowering rune by rune keeps the positions of both slices the same
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var matches []span
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			if slices.Equal(lower[i:i+len(termRunes)], termRunes) {
+				matches = append(matches, span{i, i + len(termRunes)})
+			}
+		}
+	}
+	matches = mergeSpans(matches)
+
+	start := 0
+	if len(matches) > 0 {
+		start = max(0, matches[0].start-snippetLead)
+	}
+	end := min(len(runes), start+snippetLength)
+	start = max(0, min(start, end-snippetLength))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, match := range matches {
+		if match.end <= start || match.start >= end {
+			continue
+		}
+		matchStart, matchEnd := max(match.start, start), min(match.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:matchStart])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[matchStart:matchEnd])))
+		b.WriteString("</mark>")
+		pos = matchEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// mergeSpans sorts the spans and joins the overlapping ones
+func mergeSpans(spans []span) []span {
+	slices.SortFunc(spans, func(a, b span) int { return a.start - b.start })
+
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxChatHits is the number of chats returned with the first page of messages
+const maxChatHits = 10
+
+// mongoSearchRepo searches with the text indexes of the messages and chats collections
+type mongoSearchRepo struct {
+	messageDb *mongo.Collection
+	chatDb    *mongo.Collection
+}
+
+func NewMongoSearchRepo(messageDb *mongo.Collection, chatDb *mongo.Collection) SearchRepo {
+	return &mongoSearchRepo{
+		messageDb: messageDb,
+		chatDb:    chatDb,
+	}
+}
+
+// CreateIndexes creates the text indexes, a collection can only have one
+func (r *mongoSearchRepo) CreateIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.messageDb.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "message", Value: "text"}},
+		Options: options.Index().SetName("message_text"),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.chatDb.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: "text"}},
+		Options: options.Index().SetName("name_text"),
+	})
+	return err
+}
+
+func (r *mongoSearchRepo) SearchMessages(query models.SearchQuery) ([]models.MessageSearchHit, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"$text":      bson.M{"$search": query.Text},
+		"chatId":     bson.M{"$in": objectIDs(query.ChatIDs)},
+		"deleted":    bson.M{"$ne": true},
+		"deletedFor": bson.M{"$ne": query.Viewer},
+		"expiresAt":  bson.M{"$not": bson.M{"$lte": time.Now()}},
+	}
+	if query.From != "" {
+		filter["from"] = query.From
+	}
+	createdAt := bson.M{}
+	if query.Since != nil {
+		createdAt["$gte"] = *query.Since
+	}
+	if query.Until != nil {
+		createdAt["$lt"] = *query.Until
+	}
+	if len(createdAt) > 0 {
+		filter["createdat"] = createdAt
+	}
+	if query.Before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(query.Before)
+		if err != nil {
+			return nil, false, err
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	// Read one more than asked to know if there are more
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cursor, err := r.messageDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+
+	terms := searchTerms(query.Text)
+	hits := make([]models.MessageSearchHit, 0, len(messages))
+	for _, message := range messages {
+		hits = append(hits, models.MessageSearchHit{
+			Message: message,
+			Snippet: highlight(message.Message, terms),
+		})
+	}
+	return hits, hasMore, nil
+}
+
+func (r *mongoSearchRepo) SearchChats(query models.SearchQuery) ([]models.ChatSearchHit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"$text": bson.M{"$search": query.Text},
+		"_id":   bson.M{"$in": objectIDs(query.ChatIDs)},
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"messages": 0, "score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(maxChatHits)
+
+	cursor, err := r.chatDb.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	chats := []models.Chat{}
+	if err := cursor.All(ctx, &chats); err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query.Text)
+	hits := make([]models.ChatSearchHit, 0, len(chats))
+	for _, chat := range chats {
+		hits = append(hits, models.ChatSearchHit{
+			Chat:    chat,
+			Snippet: highlight(chat.Name, terms),
+		})
+	}
+	return hits, nil
+}
+
+func objectIDs(ids []string) []primitive.ObjectID {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	return objIDs
+}
@@ -0,0 +1,12 @@
+package search
+
+import "github.com/Meeyok-Chat/backend/models"
+
+// SearchRepo finds messages and chats by their text, the caller decides which chats can be searched.
+// It is backed by Mongo text indexes, a dedicated search engine only needs another implementation.
+type SearchRepo interface {
+	CreateIndexes() error
+	// SearchMessages returns a page of matching messages, newest first, and whether there are more
+	SearchMessages(query models.SearchQuery) ([]models.MessageSearchHit, bool, error)
+	SearchChats(query models.SearchQuery) ([]models.ChatSearchHit, error)
+}
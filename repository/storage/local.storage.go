@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localStorageRepo keeps blobs on the local disk, it is meant for development and a single instance
+type localStorageRepo struct {
+	root string
+}
+
+func NewLocalStorageRepo(root string) StorageRepo {
+	return &localStorageRepo{
+		root: root,
+	}
+}
+
+// path maps the key into the root directory, refusing keys that would escape it
+func (r *localStorageRepo) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key: %s", key)
+	}
+	return filepath.Join(r.root, filepath.FromSlash(cleaned)), nil
+}
+
+func (r *localStorageRepo) Put(key string, contentType string, data []byte) error {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (r *localStorageRepo) Get(key string) (io.ReadCloser, error) {
+	path, err := r.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (r *localStorageRepo) Delete(key string) error {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3StorageRepo keeps blobs in an S3 bucket. Setting STORAGE_S3_ENDPOINT points it at any
+// S3 compatible store, e.g. https://storage.googleapis.com with HMAC keys for Google Cloud Storage.
+type s3StorageRepo struct {
+	s3Svc  *s3.S3
+	bucket string
+}
+
+func NewS3StorageRepo() (StorageRepo, error) {
+	accessKeyId := configs.GetEnv("STORAGE_S3_ACCESS_KEY_ID")
+	secretAccessKey := configs.GetEnv("STORAGE_S3_SECRET_ACCESS_KEY")
+
+	config := &aws.Config{
+		Region:      aws.String(configs.GetEnv("STORAGE_S3_REGION")),
+		Credentials: credentials.NewStaticCredentials(accessKeyId, secretAccessKey, ""),
+		MaxRetries:  aws.Int(5),
+	}
+	if endpoint := configs.GetEnv("STORAGE_S3_ENDPOINT"); endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3StorageRepo{
+		s3Svc:  s3.New(sess),
+		bucket: configs.GetEnv("STORAGE_S3_BUCKET"),
+	}, nil
+}
+
+func (r *s3StorageRepo) Put(key string, contentType string, data []byte) error {
+	_, err := r.s3Svc.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(r.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (r *s3StorageRepo) Get(key string) (io.ReadCloser, error) {
+	output, err := r.s3Svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, ErrBlobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (r *s3StorageRepo) Delete(key string) error {
+	_, err := r.s3Svc.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// StorageRepo keeps the blobs of uploaded files under slash separated keys
+type StorageRepo interface {
+	Put(key string, contentType string, data []byte) error
+	// Get opens the blob, the caller has to close it
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,60 @@
+package ticket
+
+import (
+	"sync"
+	"time"
+)
+
+type localTicket struct {
+	userID    string
+	expiresAt time.Time
+}
+
+// localTicketRepo keeps tickets in memory, it is only correct on a single instance
+type localTicketRepo struct {
+	tickets map[string]localTicket
+
+	sync.Mutex
+}
+
+func NewLocalTicketRepo() TicketRepo {
+	return &localTicketRepo{
+		tickets: make(map[string]localTicket),
+	}
+}
+
+func (r *localTicketRepo) Issue(userID string) (string, error) {
+	ticket, err := newTicket()
+	if err != nil {
+		return "", err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	// Drop expired tickets so unused ones don't pile up
+	now := time.Now()
+	for key, t := range r.tickets {
+		if now.After(t.expiresAt) {
+			delete(r.tickets, key)
+		}
+	}
+	r.tickets[ticket] = localTicket{userID: userID, expiresAt: now.Add(TicketTTL)}
+	return ticket, nil
+}
+
+func (r *localTicketRepo) Redeem(ticket string) (string, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	t, ok := r.tickets[ticket]
+	if !ok {
+		return "", ErrInvalidTicket
+	}
+	delete(r.tickets, ticket)
+
+	if time.Now().After(t.expiresAt) {
+		return "", ErrInvalidTicket
+	}
+	return t.userID, nil
+}
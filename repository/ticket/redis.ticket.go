@@ -0,0 +1,52 @@
+package ticket
+
+import (
+	"context"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/configs"
+	"github.com/go-redis/redis/v8"
+)
+
+// redisTicketRepo lets a ticket issued by one instance be redeemed on another
+type redisTicketRepo struct {
+	cache *configs.RedisClient
+}
+
+func NewRedisTicketRepo(cache *configs.RedisClient) TicketRepo {
+	return &redisTicketRepo{
+		cache: cache,
+	}
+}
+
+func ticketKey(ticket string) string {
+	return "meeyok:ws-ticket:" + ticket
+}
+
+func (r *redisTicketRepo) Issue(userID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ticket, err := newTicket()
+	if err != nil {
+		return "", err
+	}
+	if err := r.cache.Client.Set(ctx, ticketKey(ticket), userID, TicketTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+func (r *redisTicketRepo) Redeem(ticket string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// GETDEL makes sure a ticket can only be redeemed once
+	userID, err := r.cache.Client.GetDel(ctx, ticketKey(ticket)).Result()
+	if err == redis.Nil {
+		return "", ErrInvalidTicket
+	} else if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
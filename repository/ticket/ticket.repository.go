@@ -0,0 +1,29 @@
+package ticket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// TicketTTL is how long an issued ticket can be redeemed
+const TicketTTL = 30 * time.Second
+
+var ErrInvalidTicket = errors.New("invalid or expired ticket")
+
+// TicketRepo issues short-lived one-time tickets that bind a websocket upgrade
+// to a user verified by the authenticated /ws/init call
+type TicketRepo interface {
+	Issue(userID string) (string, error)
+	// Redeem returns the user of the ticket and invalidates it
+	Redeem(ticket string) (string, error)
+}
+
+func newTicket() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
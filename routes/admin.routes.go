@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"firebase.google.com/go/v4/auth"
+	"github.com/Meeyok-Chat/backend/controllers"
+	"github.com/Meeyok-Chat/backend/middleware"
+	"github.com/Meeyok-Chat/backend/services/assistant"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client *auth.Client, assistantService assistant.AssistantService) {
+	adminController := controllers.NewAdminController(assistantService)
+
+	rga := r.Group("/admin")
+	rga.Use(middleware.Auth(client), middleware.RoleAuth("admin"))
+	{
+		rga.GET("/ai-requests", adminController.GetAIRequests)
+		rga.GET("/dead-letters", adminController.GetDeadLetters)
+
+		rga.POST("/dead-letters/:id/replay", adminController.ReplayDeadLetter)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"firebase.google.com/go/v4/auth"
+	"github.com/Meeyok-Chat/backend/controllers"
+	"github.com/Meeyok-Chat/backend/middleware"
+	"github.com/Meeyok-Chat/backend/services/attachment"
+	"github.com/Meeyok-Chat/backend/services/chat"
+	"github.com/gin-gonic/gin"
+)
+
+func AttachmentRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client *auth.Client, attachmentService attachment.AttachmentService, chatService chat.ChatService) {
+	attachmentController := controllers.NewAttachmentController(attachmentService, chatService)
+
+	rga := r.Group("/chats/:id/attachments")
+	rga.Use(middleware.Auth(client))
+	{
+		rga.GET("/:attachmentId", attachmentController.GetAttachment)
+		rga.GET("/:attachmentId/thumbnail", attachmentController.GetThumbnail)
+
+		rga.POST("", attachmentController.UploadAttachment)
+	}
+}
@@ -16,16 +16,35 @@ func ChatRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client *auth
 	rgc := r.Group("/chats")
 	rgc.Use(middleware.Auth(client))
 	{
-		rgc.GET("", chatController.GetChats)
+		rgc.GET("", middleware.RoleAuth("admin"), chatController.GetChats)
+		rgc.GET("/mentions", chatController.GetMentions)
 		rgc.GET("/:id", chatController.GetChatById)
+		rgc.GET("/:id/messages", chatController.GetMessages)
+		rgc.GET("/:id/messages/:messageId/thread", chatController.GetThread)
+		rgc.GET("/:id/receipts", chatController.GetReceipts)
+		rgc.GET("/:id/pins", chatController.GetPinnedMessages)
 		rgc.GET("/user/:type", chatController.GetUserChats)
 
 		rgc.POST("", chatController.CreateChat)
+		rgc.POST("/direct", chatController.GetOrCreateDirectChat)
+		rgc.POST("/meeyok", chatController.GetOrCreateMeeyokChat)
 		rgc.POST("/:id/users", chatController.AddUsersToChat)
+		rgc.POST("/:id/leave", chatController.LeaveChat)
+		rgc.POST("/:id/messages/:messageId/reactions", chatController.AddReaction)
 
 		rgc.PUT("/:id", chatController.UpdateChat)
-		rgc.DELETE("/:id", chatController.DeleteChat)
+		rgc.PUT("/:id/admins/:userId", chatController.PromoteMember)
+		rgc.PUT("/:id/owner", chatController.TransferOwnership)
+		rgc.PUT("/:id/pins/:messageId", chatController.PinMessage)
+		rgc.PUT("/:id/retention", chatController.SetRetention)
+		rgc.PUT("/:id/ai", chatController.SetAISettings)
+		rgc.PATCH("/:id/messages/:messageId", chatController.EditMessage)
 
-		// rgc.GET("/message", chatController.GetMessages)
+		rgc.DELETE("/:id", chatController.DeleteChat)
+		rgc.DELETE("/:id/admins/:userId", chatController.DemoteMember)
+		rgc.DELETE("/:id/users/:userId", chatController.RemoveMember)
+		rgc.DELETE("/:id/pins/:messageId", chatController.UnpinMessage)
+		rgc.DELETE("/:id/messages/:messageId", chatController.DeleteMessage)
+		rgc.DELETE("/:id/messages/:messageId/reactions/:emoji", chatController.RemoveReaction)
 	}
 }
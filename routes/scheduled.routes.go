@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"firebase.google.com/go/v4/auth"
+	"github.com/Meeyok-Chat/backend/controllers"
+	"github.com/Meeyok-Chat/backend/middleware"
+	"github.com/Meeyok-Chat/backend/services/scheduler"
+	"github.com/gin-gonic/gin"
+)
+
+func ScheduledRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client *auth.Client, schedulerService scheduler.SchedulerService) {
+	scheduledController := controllers.NewScheduledController(schedulerService)
+
+	rgs := r.Group("/scheduled")
+	rgs.Use(middleware.Auth(client))
+	{
+		rgs.GET("", scheduledController.GetScheduledMessages)
+
+		rgs.POST("", scheduledController.ScheduleMessage)
+
+		rgs.PATCH("/:id", scheduledController.EditScheduledMessage)
+
+		rgs.DELETE("/:id", scheduledController.CancelScheduledMessage)
+	}
+}
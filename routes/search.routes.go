@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"firebase.google.com/go/v4/auth"
+	"github.com/Meeyok-Chat/backend/controllers"
+	"github.com/Meeyok-Chat/backend/middleware"
+	"github.com/Meeyok-Chat/backend/services/search"
+	"github.com/gin-gonic/gin"
+)
+
+func SearchRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client *auth.Client, searchService search.SearchService) {
+	searchController := controllers.NewSearchController(searchService)
+
+	rgs := r.Group("/search")
+	rgs.Use(middleware.Auth(client))
+	{
+		rgs.GET("", searchController.Search)
+	}
+}
@@ -16,6 +16,6 @@ func WebsocketRoute(r *gin.Engine, middleware middleware.AuthMiddleware, client
 	{
 		rgw.GET("/init", middleware.Auth(client), websocketController.InitWebsocket)
 		rgw.GET("/:userID", websocketController.ServeWS)
-		rgw.GET("/clients", websocketController.GetClients)
+		rgw.GET("/clients", middleware.Auth(client), middleware.RoleAuth("admin"), websocketController.GetClients)
 	}
 }
@@ -0,0 +1,152 @@
+package assistant
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/repository/queue"
+	"github.com/Meeyok-Chat/backend/repository/queue/queuePublisher"
+	Websocket "github.com/Meeyok-Chat/backend/services/websocket"
+)
+
+// monitorInterval is how often unanswered requests are looked for
+const monitorInterval = 15 * time.Second
+
+type assistantService struct {
+	aiRequestRepo    database.AIRequestRepo
+	deadLetterRepo   database.DeadLetterRepo
+	queueRepo        queue.QueueRepo
+	queuePublisher   queuePublisher.QueuePublisher
+	websocketManager Websocket.ManagerService
+}
+
+type AssistantService interface {
+	GetAIRequests(status string, before string, limit int) ([]models.AIRequest, error)
+	GetDeadLetters(query models.DeadLetterQuery) (models.DeadLetterPage, error)
+	ReplayDeadLetter(id string) (models.DeadLetter, error)
+	Monitor()
+}
+
+func NewAssistantService(aiRequestRepo database.AIRequestRepo, deadLetterRepo database.DeadLetterRepo, queueRepo queue.QueueRepo, queuePublisher queuePublisher.QueuePublisher, websocketManager Websocket.ManagerService) AssistantService {
+	return &assistantService{
+		aiRequestRepo:    aiRequestRepo,
+		deadLetterRepo:   deadLetterRepo,
+		queueRepo:        queueRepo,
+		queuePublisher:   queuePublisher,
+		websocketManager: websocketManager,
+	}
+}
+
+// GetAIRequests lists the requests made to the AI worker, newest first
+func (as *assistantService) GetAIRequests(status string, before string, limit int) ([]models.AIRequest, error) {
+	return as.aiRequestRepo.GetAIRequests(status, before, limit)
+}
+
+// GetDeadLetters lists the messages that could not be handled, newest first
+func (as *assistantService) GetDeadLetters(query models.DeadLetterQuery) (models.DeadLetterPage, error) {
+	deadLetters, hasMore, err := as.deadLetterRepo.GetDeadLetters(query)
+	if err != nil {
+		return models.DeadLetterPage{}, err
+	}
+	return models.DeadLetterPage{DeadLetters: deadLetters, HasMore: hasMore}, nil
+}
+
+// ReplayDeadLetter sends a dead letter back into the queue it came from, only once. A reply
+// that was delivered in the meantime is dropped when it arrives again.
+func (as *assistantService) ReplayDeadLetter(id string) (models.DeadLetter, error) {
+	deadLetter, err := as.deadLetterRepo.GetDeadLetter(id)
+	if err != nil {
+		return models.DeadLetter{}, err
+	}
+	// Claim the replay first, so two admins replaying at once cannot both send it
+	replayedAt, err := as.deadLetterRepo.MarkDeadLetterReplayed(deadLetter.ID)
+	if err != nil {
+		return models.DeadLetter{}, err
+	}
+	if err := as.queueRepo.Send(deadLetter.Queue, []byte(deadLetter.Body)); err != nil {
+		if err := as.deadLetterRepo.UnmarkDeadLetterReplayed(deadLetter.ID); err != nil {
+			log.Printf("Failed to release replay of dead letter %s: %v", id, err)
+		}
+		return models.DeadLetter{}, err
+	}
+	deadLetter.ReplayedAt = &replayedAt
+	return deadLetter, nil
+}
+
+// Monitor publishes again the requests the AI worker did not answer in time and gives up on
+// them after a few attempts, it runs for the lifetime of the server. Every instance can run it,
+// a request is only claimed by one of them.
+func (as *assistantService) Monitor() {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		as.retryExpired()
+	}
+}
+
+// retryExpired handles every request that timed out right now
+func (as *assistantService) retryExpired() {
+	for {
+		request, ok, err := as.aiRequestRepo.ClaimExpiredRequest(time.Now(), models.AIRequestTimeout)
+		if err != nil {
+			log.Printf("Failed to claim expired AI request: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		if request.Attempts > models.AIRequestMaxAttempts {
+			as.giveUp(request)
+			continue
+		}
+		log.Printf("AI request %s was not answered, publishing it again (attempt %d/%d)", request.ID, request.Attempts, models.AIRequestMaxAttempts)
+		if err := as.republish(request); err != nil {
+			log.Printf("Failed to publish AI request %s again: %v", request.ID, err)
+		}
+	}
+}
+
+// republish publishes the request again under the correlation ID of its current attempt,
+// deltas of the attempts before are then buffered apart from the deltas of this one
+func (as *assistantService) republish(request models.AIRequest) error {
+	var event models.Event
+	if err := json.Unmarshal([]byte(request.Payload), &event); err != nil {
+		return err
+	}
+	var payload models.QueuePublisherPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+	payload.CorrelationID = models.AttemptCorrelationID(request.ID, request.Attempts)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event.Payload = data
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return as.queuePublisher.Publish(body)
+}
+
+// giveUp times the request out and lets the chat know Meeyok AI could not answer it
+func (as *assistantService) giveUp(request models.AIRequest) {
+	timedOut, err := as.aiRequestRepo.SetAIRequestStatus(request.ID, models.AIRequestTimedOut, "no reply from the AI worker", models.AIRequestPending)
+	if err != nil {
+		log.Printf("Failed to time out AI request %s: %v", request.ID, err)
+		return
+	}
+	if !timedOut {
+		return
+	}
+	if err := as.websocketManager.SendBotMessage(request.ChatID.Hex(), models.AIFailureMessage); err != nil {
+		log.Printf("Failed to send failure of AI request %s: %v", request.ID, err)
+	}
+}
@@ -0,0 +1,189 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/repository/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// allowedContentTypes are the sniffed types an attachment can have
+var allowedContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"video/mp4",
+	"video/webm",
+	"audio/mpeg",
+	"audio/wave",
+	"audio/ogg",
+	"application/pdf",
+	"application/zip",
+	"text/plain",
+}
+
+type attachmentService struct {
+	attachmentRepo database.AttachmentRepo
+	storageRepo    storage.StorageRepo
+}
+
+type AttachmentService interface {
+	Upload(chatID string, userID string, fileName string, data []byte) (models.Attachment, error)
+	GetAttachment(chatID string, attachmentID string) (models.Attachment, error)
+	Open(attachment models.Attachment, thumbnail bool) (io.ReadCloser, error)
+	ClaimAttachments(message models.Message, attachments []models.Attachment) ([]models.Attachment, error)
+	ReleaseAttachments(message models.Message) error
+	DeleteAttachments(attachments []models.Attachment) error
+}
+
+func NewAttachmentService(attachmentRepo database.AttachmentRepo, storageRepo storage.StorageRepo) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		storageRepo:    storageRepo,
+	}
+}
+
+// Upload validates the file, stores it with a thumbnail when it is an image and records the attachment.
+// The content type is sniffed from the data, the type the client claims is never trusted.
+func (as *attachmentService) Upload(chatID string, userID string, fileName string, data []byte) (models.Attachment, error) {
+	chatObjID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return models.Attachment{}, models.ErrChatNotFound
+	}
+	if len(data) == 0 {
+		return models.Attachment{}, fmt.Errorf("file is empty")
+	}
+	if len(data) > models.MaxAttachmentSize {
+		return models.Attachment{}, models.ErrAttachmentTooLarge
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !slices.Contains(allowedContentTypes, contentType) {
+		return models.Attachment{}, models.ErrUnsupportedAttachment
+	}
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		ChatID:      chatObjID,
+		UploadedBy:  userID,
+		FileName:    path.Base("/" + fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+	attachment.Key = fmt.Sprintf("attachments/%s/%s", chatID, attachment.ID.Hex())
+
+	if err := as.storageRepo.Put(attachment.Key, contentType, data); err != nil {
+		return models.Attachment{}, fmt.Errorf("failed to store attachment: %v", err)
+	}
+
+	if thumbnail, width, height, ok := makeThumbnail(data); ok {
+		attachment.Width = width
+		attachment.Height = height
+		// A sibling of the blob, a key below it would need the blob to be a directory
+		attachment.ThumbnailKey = attachment.Key + ".thumb"
+		if err := as.storageRepo.Put(attachment.ThumbnailKey, "image/jpeg", thumbnail); err != nil {
+			as.deleteBlobs(attachment)
+			return models.Attachment{}, fmt.Errorf("failed to store thumbnail: %v", err)
+		}
+		attachment.HasThumbnail = true
+	}
+
+	if err := as.attachmentRepo.InsertAttachment(attachment); err != nil {
+		as.deleteBlobs(attachment)
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// deleteBlobs removes what an upload that failed halfway already stored
+func (as *attachmentService) deleteBlobs(attachment models.Attachment) {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := as.storageRepo.Delete(key); err != nil {
+			log.Printf("failed to delete blob %s of a failed upload: %v", key, err)
+		}
+	}
+}
+
+func (as *attachmentService) GetAttachment(chatID string, attachmentID string) (models.Attachment, error) {
+	return as.attachmentRepo.GetAttachment(chatID, attachmentID)
+}
+
+// Open reads the file of the attachment, or its thumbnail
+func (as *attachmentService) Open(attachment models.Attachment, thumbnail bool) (io.ReadCloser, error) {
+	key := attachment.Key
+	if thumbnail {
+		if !attachment.HasThumbnail {
+			return nil, models.ErrAttachmentNotFound
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	reader, err := as.storageRepo.Get(key)
+	if err == storage.ErrBlobNotFound {
+		return nil, models.ErrAttachmentNotFound
+	}
+	return reader, err
+}
+
+// ClaimAttachments binds the attachments referenced by a message being sent to it,
+// only attachments the sender uploaded into the same chat and never sent before can be used
+func (as *attachmentService) ClaimAttachments(message models.Message, attachments []models.Attachment) ([]models.Attachment, error) {
+	if len(attachments) > models.MaxMessageAttachments {
+		return nil, models.ErrTooManyAttachments
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.ID.IsZero() {
+			return nil, models.ErrAttachmentNotFound
+		}
+		if !slices.Contains(ids, attachment.ID) {
+			ids = append(ids, attachment.ID)
+		}
+	}
+	return as.attachmentRepo.ClaimAttachments(message.ChatID, message.From, ids, message.ID)
+}
+
+// ReleaseAttachments frees the attachments claimed by a message that could not be stored,
+// so they can be sent again
+func (as *attachmentService) ReleaseAttachments(message models.Message) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+	return as.attachmentRepo.ReleaseAttachments(message.ID)
+}
+
+// DeleteAttachments removes the blobs and the records of attachments whose message is gone
+func (as *attachmentService) DeleteAttachments(attachments []models.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(attachments))
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := as.storageRepo.Delete(key); err != nil {
+				return err
+			}
+		}
+		ids = append(ids, attachment.ID)
+	}
+	return as.attachmentRepo.DeleteAttachments(ids)
+}
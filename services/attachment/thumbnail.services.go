@@ -0,0 +1,81 @@
+package attachment
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// thumbnailSize is the longest side of a thumbnail
+	thumbnailSize = 320
+	// maxImagePixels keeps a small file claiming huge dimensions from exhausting memory
+	maxImagePixels = 50_000_000
+)
+
+// makeThumbnail scales an image down to a JPEG thumbnail and returns the size of the original,
+// ok is false when the data is not an image the standard library can decode
+func makeThumbnail(data []byte) (thumbnail []byte, width int, height int, ok bool) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width == 0 || config.Height == 0 || config.Width*config.Height > maxImagePixels {
+		return nil, 0, 0, false
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, false
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaleDown(img, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, 0, 0, false
+	}
+	return buf.Bytes(), config.Width, config.Height, true
+}
+
+// scaleDown shrinks the image so its longest side fits in size, averaging the
+// source pixels that fall into each thumbnail pixel. JPEG has no alpha channel,
+// so transparent pixels are laid over white.
+func scaleDown(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			// The colors are alpha-premultiplied, adding the missing coverage blends with white
+			background := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + background),
+				G: uint16(g/n + background),
+				B: uint16(b/n + background),
+				A: 0xffff,
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/Meeyok-Chat/backend/dtos"
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// GetOrCreateMeeyokChat returns the chat of the user with Meeyok AI, creating it
+// only when the user does not have one yet. created reports which happened.
+func (cs *chatService) GetOrCreateMeeyokChat(userID string) (models.Chat, bool, error) {
+	if _, err := cs.userRepo.GetUserByID(userID); err != nil {
+		return models.Chat{}, false, err
+	}
+
+	chat, created, err := cs.chatRepo.CreateMeeyokChat(userID)
+	if err != nil {
+		return models.Chat{}, false, err
+	}
+	if created {
+		if err := cs.userRepo.AddChatToUser(userID, chat.ID.Hex()); err != nil {
+			return models.Chat{}, false, err
+		}
+	}
+	chat.Messages = []models.Message{}
+	return chat, created, nil
+}
+
+// SetAISettings turns Meeyok AI on or off in the chat and changes the persona it follows,
+// only the owner and admins of a group can change them. changed is false when nothing changed,
+// toggled is set when the AI was turned on or off.
+func (cs *chatService) SetAISettings(chatID string, userID string, req dtos.SetAISettingsRequest) (chat models.Chat, changed bool, toggled bool, err error) {
+	chat, err = cs.Authorize(chatID, userID, models.PermissionConfigureAI)
+	if err != nil {
+		return models.Chat{}, false, false, err
+	}
+
+	disabled, persona := chat.AIDisabled, chat.AIPersona
+	if req.Enabled != nil {
+		if !*req.Enabled && chat.Type == models.MeeyokChatType {
+			return models.Chat{}, false, false, models.ErrAIRequired
+		}
+		disabled = !*req.Enabled
+	}
+	if req.Persona != nil {
+		persona = strings.TrimSpace(*req.Persona)
+	}
+	if disabled == chat.AIDisabled && persona == chat.AIPersona {
+		return chat, false, false, nil
+	}
+
+	if err := cs.chatRepo.SetAISettings(chatID, disabled, persona); err != nil {
+		return models.Chat{}, false, false, err
+	}
+	toggled = disabled != chat.AIDisabled
+	chat.AIDisabled, chat.AIPersona = disabled, persona
+	return chat, true, toggled, nil
+}
@@ -2,32 +2,88 @@ package chat
 
 import (
 	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/Meeyok-Chat/backend/dtos"
 	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/cache"
 	"github.com/Meeyok-Chat/backend/repository/database"
 )
 
 type chatService struct {
-	chatRepo database.ChatRepo
+	chatRepo    database.ChatRepo
+	messageRepo database.MessageRepo
+	userRepo    database.UserRepo
+	// readMarkerRepo and unreadCacheRepo keep the unread counters of every user and chat
+	readMarkerRepo  database.ReadMarkerRepo
+	unreadCacheRepo cache.UnreadCacheRepo
 }
 
 type ChatService interface {
+	CheckMember(chatID string, userID string) error
+
 	GetChats() ([]models.Chat, error)
-	GetChatById(id string, page int, numberOfMessages int) (models.Chat, error)
+	GetChatById(id string, userID string, page int, numberOfMessages int) (models.Chat, error)
 	GetUserChats(userID string, chatType string) ([]models.Chat, error)
-	GetMessages(id string, page int, numberOfMessages int) ([]models.Message, error)
-	CreateChat(chat dtos.CreateChatRequest) (models.Chat, error)
+	GetMessages(id string, query models.MessageQuery) (models.MessagePage, error)
+	GetThread(id string, rootID string, query models.MessageQuery) (models.ThreadPage, error)
+	GetReceipts(id string, query models.MessageQuery) ([]models.MessageReceipts, error)
+	GetUnreadMentions(userID string, query models.MessageQuery) (models.MessagePage, error)
+	ParseMentions(members []string, text string) []models.Mention
+	AssembleContext(chat models.Chat, trigger models.Message) (models.QueuePublisherPayload, error)
+	CountNewMessage(message models.Message, members []string) map[string]int
+	MarkMessages(chatID string, userID string, upToMessageID string, status string) ([]models.Message, error)
+	MarkRead(chatID string, userID string, messageID string) (int, string, error)
+	EditMessage(chatID string, messageID string, userID string, text string) (models.Message, error)
+	DeleteMessage(chatID string, messageID string, userID string, forEveryone bool) (models.Message, error)
+	AddReaction(chatID string, messageID string, userID string, emoji string) (models.Message, error)
+	RemoveReaction(chatID string, messageID string, userID string, emoji string) (models.Message, error)
+	PinMessage(chatID string, messageID string, userID string) (models.Pin, bool, error)
+	UnpinMessage(chatID string, messageID string, userID string) (bool, error)
+	GetPinnedMessages(chatID string, viewer string) ([]models.PinnedMessage, error)
+	SetMessageTTL(chatID string, userID string, ttl time.Duration) (bool, error)
+	ExpireMessages(now time.Time, limit int) ([]models.ExpiredMessages, error)
+	Authorize(chatID string, userID string, permission models.Permission) (models.Chat, error)
+	Promote(chatID string, actorID string, userID string) error
+	Demote(chatID string, actorID string, userID string) error
+	TransferOwnership(chatID string, ownerID string, newOwnerID string) error
+	LeaveChat(chatID string, userID string) (models.MemberRemoval, error)
+	RemoveMember(chatID string, actorID string, userID string) (models.MemberRemoval, error)
+	CreateChat(chat dtos.CreateChatRequest, ownerID string) (models.Chat, error)
+	GetOrCreateDirectChat(userID string, otherUserID string) (models.Chat, bool, error)
+	GetOrCreateMeeyokChat(userID string) (models.Chat, bool, error)
+	SetAISettings(chatID string, userID string, req dtos.SetAISettingsRequest) (models.Chat, bool, bool, error)
 	AddUsersToChat(chatID string, users []string) error
 	UpdateChat(chat models.Chat) error
-	DeleteChat(id string) error
+	DeleteChat(id string) ([]string, error)
 	TrimMessages(chat models.Chat) models.Chat
 }
 
-func NewChatService(chatRepo database.ChatRepo) ChatService {
+func NewChatService(chatRepo database.ChatRepo, messageRepo database.MessageRepo, userRepo database.UserRepo, readMarkerRepo database.ReadMarkerRepo, unreadCacheRepo cache.UnreadCacheRepo) ChatService {
 	return &chatService{
-		chatRepo: chatRepo,
+		chatRepo:        chatRepo,
+		messageRepo:     messageRepo,
+		userRepo:        userRepo,
+		readMarkerRepo:  readMarkerRepo,
+		unreadCacheRepo: unreadCacheRepo,
+	}
+}
+
+// CheckMember is the authorization gate of every chat read and write path,
+// it returns models.ErrNotChatMember when the user is not in the chat
+func (cs *chatService) CheckMember(chatID string, userID string) error {
+	users, err := cs.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(users, userID) {
+		return models.ErrNotChatMember
 	}
+	return nil
 }
 
 func (cs *chatService) GetChats() ([]models.Chat, error) {
@@ -38,58 +94,324 @@ func (cs *chatService) GetChats() ([]models.Chat, error) {
 	return chats, nil
 }
 
-func (cs *chatService) GetChatById(id string, page int, numberOfMessages int) (models.Chat, error) {
+// GetChatById returns the chat with one page of its latest messages as seen by the user,
+// pages start at 1
+func (cs *chatService) GetChatById(id string, userID string, page int, numberOfMessages int) (models.Chat, error) {
 	chat, err := cs.chatRepo.GetChatByID(id)
 	if err != nil {
 		return models.Chat{}, err
 	}
-	if page == 0 {
-		return chat, nil
+
+	query := models.MessageQuery{
+		Viewer: userID,
+		Skip:   (page - 1) * numberOfMessages,
+		Limit:  numberOfMessages,
+	}
+	chat.Messages, _, err = cs.messageRepo.GetMessages(id, query)
+	if err != nil {
+		return models.Chat{}, err
 	}
-	chat.Messages = chat.Messages[max(0, len(chat.Messages)-page*numberOfMessages):max(0, len(chat.Messages)-page*numberOfMessages+numberOfMessages)]
 	return chat, nil
 }
 
-func (cs *chatService) GetMessages(id string, page int, numberOfMessages int) ([]models.Message, error) {
-	chat, err := cs.chatRepo.GetChatByID(id)
+// GetMessages returns the page of messages selected by the before or after cursor,
+// without a cursor it returns the latest messages
+func (cs *chatService) GetMessages(id string, query models.MessageQuery) (models.MessagePage, error) {
+	messages, hasMore, err := cs.messageRepo.GetMessages(id, query)
+	if err != nil {
+		return models.MessagePage{}, err
+	}
+	return models.MessagePage{Messages: messages, HasMore: hasMore}, nil
+}
+
+// GetThread returns the root message of a thread with a page of its replies
+func (cs *chatService) GetThread(id string, rootID string, query models.MessageQuery) (models.ThreadPage, error) {
+	root, err := cs.messageRepo.GetMessage(id, rootID)
+	if err != nil {
+		return models.ThreadPage{}, err
+	}
+	if root.ThreadID != nil {
+		return models.ThreadPage{}, models.ErrNotThreadRoot
+	}
+
+	query.ThreadID = rootID
+	messages, hasMore, err := cs.messageRepo.GetMessages(id, query)
+	if err != nil {
+		return models.ThreadPage{}, err
+	}
+	return models.ThreadPage{Root: root, Messages: messages, HasMore: hasMore}, nil
+}
+
+func (cs *chatService) GetReceipts(id string, query models.MessageQuery) ([]models.MessageReceipts, error) {
+	messages, _, err := cs.messageRepo.GetMessages(id, query)
 	if err != nil {
 		return nil, err
 	}
-	if page == 0 {
-		return chat.Messages, nil
+
+	receipts := []models.MessageReceipts{}
+	for _, message := range messages {
+		receipt := models.MessageReceipts{
+			MessageID:   message.ID,
+			From:        message.From,
+			DeliveredTo: message.DeliveredTo,
+			ReadBy:      message.ReadBy,
+		}
+		if receipt.DeliveredTo == nil {
+			receipt.DeliveredTo = []models.Receipt{}
+		}
+		if receipt.ReadBy == nil {
+			receipt.ReadBy = []models.Receipt{}
+		}
+		receipts = append(receipts, receipt)
 	}
-	messages := chat.Messages[max(0, len(chat.Messages)-page*numberOfMessages):max(0, len(chat.Messages)-(page-1)*numberOfMessages)]
-	return messages, nil
+	return receipts, nil
 }
 
+// GetUnreadMentions returns a page of the messages mentioning the user that the user has not read yet,
+// across every chat the user is a member of
+func (cs *chatService) GetUnreadMentions(userID string, query models.MessageQuery) (models.MessagePage, error) {
+	chatIDs, err := cs.chatRepo.GetUserChatIDs(userID)
+	if err != nil {
+		return models.MessagePage{}, err
+	}
+	if len(chatIDs) == 0 {
+		return models.MessagePage{Messages: []models.Message{}}, nil
+	}
+
+	lastRead, err := cs.readMarkerRepo.GetReadMarkers(userID, chatIDs)
+	if err != nil {
+		return models.MessagePage{}, err
+	}
+
+	messages, hasMore, err := cs.messageRepo.GetUnreadMentions(userID, chatIDs, lastRead, query)
+	if err != nil {
+		return models.MessagePage{}, err
+	}
+	return models.MessagePage{Messages: messages, HasMore: hasMore}, nil
+}
+
+// GetUserChats returns the chats of the user with their unread counters
 func (cs *chatService) GetUserChats(userID string, chatType string) ([]models.Chat, error) {
+	var chats []models.Chat
+	var err error
 	switch chatType {
 	case "group":
-		return cs.chatRepo.GetGroupChats(userID)
+		chats, err = cs.chatRepo.GetGroupChats(userID)
 	case "friend":
-		return cs.chatRepo.GetFriendChats(userID)
+		chats, err = cs.chatRepo.GetFriendChats(userID)
 	case "non-friend":
-		return cs.chatRepo.GetNonFriendChats(userID)
+		chats, err = cs.chatRepo.GetNonFriendChats(userID)
+	case "meeyok":
+		var chat models.Chat
+		chat, err = cs.chatRepo.GetMeeyokChat(userID)
+		if err == nil {
+			chats = []models.Chat{chat}
+		} else if errors.Is(err, models.ErrChatNotFound) {
+			chats, err = []models.Chat{}, nil
+		}
 	default:
 		return nil, errors.New("invalid chat type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.fillUnreadCounts(userID, chats); err != nil {
+		return nil, err
+	}
+	return chats, nil
 }
 
-func (cs *chatService) CreateChat(chatDto dtos.CreateChatRequest) (models.Chat, error) {
+// EditMessage changes the text of a message, only its sender can edit it
+func (cs *chatService) EditMessage(chatID string, messageID string, userID string, text string) (models.Message, error) {
+	if strings.TrimSpace(text) == "" {
+		return models.Message{}, models.ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(text) > models.MaxMessageRunes {
+		return models.Message{}, models.ErrMessageTooLong
+	}
+
+	message, err := cs.messageRepo.GetMessage(chatID, messageID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if message.Deleted {
+		return models.Message{}, models.ErrMessageNotFound
+	}
+	if message.From != userID {
+		return models.Message{}, models.ErrNotMessageSender
+	}
+
+	users, err := cs.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	return cs.messageRepo.EditMessage(message, text, cs.ParseMentions(users, text))
+}
+
+// DeleteMessage hides a message for the user, or turns it into a tombstone for
+// everyone, which only its sender can do
+func (cs *chatService) DeleteMessage(chatID string, messageID string, userID string, forEveryone bool) (models.Message, error) {
+	message, err := cs.messageRepo.GetMessage(chatID, messageID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	if !forEveryone {
+		if err := cs.messageRepo.DeleteMessageForUser(message, userID); err != nil {
+			return models.Message{}, err
+		}
+		cs.forgetUnreadCounts(chatID, []string{userID})
+		return message, nil
+	}
+
+	if message.From != userID {
+		return models.Message{}, models.ErrNotMessageSender
+	}
+	if message.Deleted {
+		return message, nil
+	}
+	deleted, err := cs.messageRepo.DeleteMessageForEveryone(message)
+	if err != nil {
+		return models.Message{}, err
+	}
+	// Clients drop the pin of a deleted message with the message_deleted event
+	if _, err := cs.chatRepo.UnpinMessage(chatID, message.ID); err != nil {
+		return models.Message{}, err
+	}
+	// The message may have been unread for any member
+	if users, err := cs.chatRepo.GetChatUsers(chatID); err == nil {
+		cs.forgetUnreadCounts(chatID, users)
+	}
+	return deleted, nil
+}
+
+func (cs *chatService) AddReaction(chatID string, messageID string, userID string, emoji string) (models.Message, error) {
+	if !validEmoji(emoji) {
+		return models.Message{}, models.ErrInvalidEmoji
+	}
+
+	message, err := cs.messageRepo.GetMessage(chatID, messageID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if message.Deleted {
+		return models.Message{}, models.ErrMessageNotFound
+	}
+	return cs.messageRepo.AddReaction(message, userID, emoji)
+}
+
+func (cs *chatService) RemoveReaction(chatID string, messageID string, userID string, emoji string) (models.Message, error) {
+	if !validEmoji(emoji) {
+		return models.Message{}, models.ErrInvalidEmoji
+	}
+
+	message, err := cs.messageRepo.GetMessage(chatID, messageID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	return cs.messageRepo.RemoveReaction(message, userID, emoji)
+}
+
+// emojiPictographs are the blocks of pictographic characters an emoji is built from
+var emojiPictographs = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00A9, Hi: 0x00A9, Stride: 1},
+		{Lo: 0x00AE, Hi: 0x00AE, Stride: 1},
+		{Lo: 0x203C, Hi: 0x203C, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x21AA, Stride: 1},
+		{Lo: 0x231A, Hi: 0x23FF, Stride: 1},
+		{Lo: 0x24C2, Hi: 0x24C2, Stride: 1},
+		{Lo: 0x25AA, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2B05, Hi: 0x2B55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303D, Hi: 0x303D, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		// Flags, skin tones and every other emoji block
+		{Lo: 0x1F000, Hi: 0x1FAFF, Stride: 1},
+	},
+}
+
+const (
+	emojiJoiner            = '\u200D'
+	emojiVariationSelector = '\uFE0F'
+	emojiKeycap            = '\u20E3'
+)
+
+// validEmoji accepts a single emoji, joined sequences like families and flags included.
+// Emoji are never a field name Mongo refuses.
+func validEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > 32 {
+		return false
+	}
+	pictographs := 0
+	for i, r := range emoji {
+		switch {
+		case unicode.Is(emojiPictographs, r):
+			pictographs++
+		case r == emojiJoiner, r == emojiVariationSelector, r == emojiKeycap, r >= 0xE0020 && r <= 0xE007F:
+			// Joins, presents or tags the pictographs around it
+		case i == 0 && strings.ContainsRune("0123456789#*", r) && strings.HasSuffix(emoji, string(emojiKeycap)):
+			pictographs++
+		default:
+			return false
+		}
+	}
+	return pictographs > 0
+}
+
+// CreateChat creates the chat, the user creating a group chat owns it
+func (cs *chatService) CreateChat(chatDto dtos.CreateChatRequest, ownerID string) (models.Chat, error) {
 	chat := models.Chat{
-		Name:     chatDto.Name,
-		Users:    chatDto.Users,
-		Type:     chatDto.Type,
-		Messages: []models.Message{},
+		Name:  chatDto.Name,
+		Users: chatDto.Users,
+		Type:  chatDto.Type,
+	}
+	if chat.Type == models.GroupChatType {
+		chat.Owner = ownerID
 	}
 
 	result, err := cs.chatRepo.CreateChat(chat)
 	if err != nil {
 		return models.Chat{}, err
 	}
+	result.Messages = []models.Message{}
 	return result, nil
 }
 
+// GetOrCreateDirectChat returns the individual chat of the two users, creating it
+// when missing. created reports whether a new chat was made for the pair.
+func (cs *chatService) GetOrCreateDirectChat(userID string, otherUserID string) (models.Chat, bool, error) {
+	if userID == "" || otherUserID == "" || userID == otherUserID {
+		return models.Chat{}, false, models.ErrInvalidDirect
+	}
+	for _, id := range []string{userID, otherUserID} {
+		if _, err := cs.userRepo.GetUserByID(id); err != nil {
+			return models.Chat{}, false, err
+		}
+	}
+
+	chat, created, err := cs.chatRepo.CreateDirectChat(userID, otherUserID)
+	if err != nil {
+		return models.Chat{}, false, err
+	}
+	if created {
+		for _, id := range chat.Users {
+			if err := cs.userRepo.AddChatToUser(id, chat.ID.Hex()); err != nil {
+				return models.Chat{}, false, err
+			}
+		}
+	}
+	chat.Messages = []models.Message{}
+	return chat, created, nil
+}
+
 func (cs *chatService) AddUsersToChat(chatID string, users []string) error {
 	err := cs.chatRepo.AddUsersToChat(chatID, users)
 	if err != nil {
@@ -106,12 +428,25 @@ func (cs *chatService) UpdateChat(chat models.Chat) error {
 	return nil
 }
 
-func (cs *chatService) DeleteChat(id string) error {
-	err := cs.chatRepo.DeleteChat(id)
+// DeleteChat deletes the chat with its messages and takes it out of the chats of its members,
+// it returns the members so they can be told the chat is gone
+func (cs *chatService) DeleteChat(id string) ([]string, error) {
+	users, err := cs.chatRepo.GetChatUsers(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	err = cs.chatRepo.DeleteChat(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.userRepo.RemoveChatFromUsers(users, id); err != nil {
+		return nil, err
+	}
+	cs.forgetUnreadCounts(id, users)
+	if err := cs.readMarkerRepo.DeleteChatReadMarkers(id); err != nil {
+		return nil, err
+	}
+	return users, cs.messageRepo.DeleteChatMessages(id)
 }
 
 func (cs *chatService) TrimMessages(chat models.Chat) models.Chat {
@@ -0,0 +1,34 @@
+package chat
+
+import "testing"
+
+func TestValidEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		valid bool
+	}{
+		{name: "empty", emoji: "", valid: false},
+		{name: "single emoji", emoji: "👍", valid: true},
+		{name: "skin tone", emoji: "👍🏽", valid: true},
+		{name: "variation selector", emoji: "❤️", valid: true},
+		{name: "joined family", emoji: "👨‍👩‍👧", valid: true},
+		{name: "flag", emoji: "🇹🇭", valid: true},
+		{name: "keycap", emoji: "1️⃣", valid: true},
+		{name: "tag sequence", emoji: "🏴󠁧󠁢󠁳󠁣󠁴󠁿", valid: true},
+		{name: "letter", emoji: "a", valid: false},
+		{name: "digit without keycap", emoji: "1", valid: false},
+		{name: "emoji with text", emoji: "👍ok", valid: false},
+		{name: "field path", emoji: "a.b", valid: false},
+		{name: "operator", emoji: "$set", valid: false},
+		{name: "joiner only", emoji: "‍", valid: false},
+		{name: "too long", emoji: "👍👍👍👍👍👍👍👍👍", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validEmoji(tt.emoji); got != tt.valid {
+				t.Errorf("validEmoji(%q) = %v, want %v", tt.emoji, got, tt.valid)
+			}
+		})
+	}
+}
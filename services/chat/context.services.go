@@ -0,0 +1,148 @@
+package chat
+
+import (
+	"log"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+const (
+	// bytesPerToken is a rough count of the bytes of text in a token of the model
+	bytesPerToken = 4
+	// messageOverheadTokens accounts for the sender and the other fields of every message
+	messageOverheadTokens = 8
+)
+
+// AssembleContext packages what the AI worker needs to reply to the trigger message:
+// the chat with its members, and the messages before the trigger that fit the budget.
+// The newest messages are kept when the budget runs out.
+func (cs *chatService) AssembleContext(chat models.Chat, trigger models.Message) (models.QueuePublisherPayload, error) {
+	messages, hasMore, err := cs.messageRepo.GetMessages(chat.ID.Hex(), models.MessageQuery{
+		Before: trigger.ID.Hex(),
+		Limit:  models.AIContextMaxMessages,
+	})
+	if err != nil {
+		return models.QueuePublisherPayload{}, err
+	}
+
+	userIDs := slices.Clone(chat.Users)
+	for _, message := range messages {
+		if !slices.Contains(userIDs, message.From) {
+			userIDs = append(userIDs, message.From)
+		}
+	}
+	names := cs.displayNames(userIDs)
+
+	payload := models.QueuePublisherPayload{
+		Version: models.QueuePayloadVersion,
+		From:    chat.ID.Hex(),
+		Chat: models.AIChatContext{
+			ID:      chat.ID.Hex(),
+			Name:    chat.Name,
+			Type:    chat.Type,
+			Persona: chat.AIPersona,
+		},
+		Truncated: hasMore,
+	}
+	for _, userID := range chat.Users {
+		payload.Chat.Members = append(payload.Chat.Members, models.AIContextMember{
+			UserID:      userID,
+			DisplayName: names[userID],
+			Role:        chat.Role(userID),
+		})
+	}
+
+	var truncated bool
+	payload.Trigger, truncated = contextMessage(chat, trigger, names)
+	payload.Truncated = payload.Truncated || truncated
+	budget := models.AIContextMaxTokens - estimateTokens(payload.Trigger.Text)
+	payload.Messages, truncated = fitBudget(chat, messages, names, budget)
+	payload.Truncated = payload.Truncated || truncated
+	return payload, nil
+}
+
+// fitBudget walks back from the newest message until the budget is spent and returns the
+// messages it kept oldest first. It reports whether a message was left out or cut.
+func fitBudget(chat models.Chat, messages []models.Message, names map[string]string, budget int) ([]models.AIContextMessage, bool) {
+	kept := []models.AIContextMessage{}
+	truncated := false
+	for _, message := range slices.Backward(messages) {
+		if message.Deleted {
+			continue
+		}
+		contextMsg, cut := contextMessage(chat, message, names)
+		tokens := estimateTokens(contextMsg.Text)
+		if tokens > budget {
+			truncated = true
+			break
+		}
+		budget -= tokens
+		truncated = truncated || cut
+		kept = append(kept, contextMsg)
+	}
+	slices.Reverse(kept)
+	return kept, truncated
+}
+
+// contextMessage converts a message for the AI worker, and reports whether its text was cut
+func contextMessage(chat models.Chat, message models.Message, names map[string]string) (models.AIContextMessage, bool) {
+	contextMsg := models.AIContextMessage{
+		ID:          message.ID.Hex(),
+		From:        message.From,
+		DisplayName: names[message.From],
+		Text:        message.Message,
+		CreatedAt:   message.CreatedAt,
+	}
+	switch message.From {
+	case models.MeeyokAI:
+		contextMsg.DisplayName = models.MeeyokAI
+		contextMsg.Role = models.AIRoleAssistant
+	case models.SystemSender:
+		contextMsg.DisplayName = models.SystemSender
+		contextMsg.Role = models.AIRoleSystem
+	default:
+		contextMsg.Role = chat.Role(message.From)
+	}
+	if message.ReplyTo != nil {
+		contextMsg.ReplyTo = message.ReplyTo.Hex()
+	}
+	for _, attachment := range message.Attachments {
+		contextMsg.Attachments = append(contextMsg.Attachments, models.AIContextAttachment{
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+		})
+	}
+
+	truncated := false
+	if utf8.RuneCountInString(contextMsg.Text) > models.AIContextMaxMessageRunes {
+		contextMsg.Text = string([]rune(contextMsg.Text)[:models.AIContextMaxMessageRunes]) + "…"
+		truncated = true
+	}
+	return contextMsg, truncated
+}
+
+// displayNames maps the users to their usernames, users that cannot be found keep their ID
+func (cs *chatService) displayNames(userIDs []string) map[string]string {
+	names := make(map[string]string, len(userIDs))
+	for _, userID := range userIDs {
+		names[userID] = userID
+	}
+	users, err := cs.userRepo.GetUsersByIDs(userIDs)
+	if err != nil {
+		log.Printf("failed to get usernames for the AI context: %v", err)
+		return names
+	}
+	for _, user := range users {
+		if user.Username != "" {
+			names[user.ID.Hex()] = user.Username
+		}
+	}
+	return names
+}
+
+// estimateTokens is a rough token count of the text, good enough for budgeting
+func estimateTokens(text string) int {
+	return messageOverheadTokens + (len(text)+bytesPerToken-1)/bytesPerToken
+}
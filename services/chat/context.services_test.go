@@ -0,0 +1,116 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// textOfTokens returns a text estimateTokens counts as the given number of tokens
+func textOfTokens(tokens int) string {
+	return strings.Repeat("a", (tokens-messageOverheadTokens)*bytesPerToken)
+}
+
+func TestFitBudget(t *testing.T) {
+	chat := models.Chat{Type: models.GroupChatType, Owner: "owner"}
+	message := func(text string, deleted bool) models.Message {
+		return models.Message{ID: primitive.NewObjectID(), From: "owner", Message: text, Deleted: deleted}
+	}
+	long := strings.Repeat("x", models.AIContextMaxMessageRunes+1)
+
+	tests := []struct {
+		name      string
+		messages  []models.Message
+		budget    int
+		kept      []string
+		truncated bool
+	}{
+		{
+			name:     "everything fits",
+			messages: []models.Message{message("one", false), message("two", false)},
+			budget:   100,
+			kept:     []string{"one", "two"},
+		},
+		{
+			name:     "exactly the budget",
+			messages: []models.Message{message(textOfTokens(10), false), message(textOfTokens(10), false)},
+			budget:   20,
+			kept:     []string{textOfTokens(10), textOfTokens(10)},
+		},
+		{
+			name:      "newest messages are kept",
+			messages:  []models.Message{message("old", false), message(textOfTokens(15), false), message("new", false)},
+			budget:    estimateTokens("new") + 15,
+			kept:      []string{textOfTokens(15), "new"},
+			truncated: true,
+		},
+		{
+			name:      "an older message that would fit is left out too",
+			messages:  []models.Message{message("old", false), message(textOfTokens(50), false), message("new", false)},
+			budget:    estimateTokens("new") + estimateTokens("old"),
+			kept:      []string{"new"},
+			truncated: true,
+		},
+		{
+			name:     "deleted messages cost nothing",
+			messages: []models.Message{message("kept", false), message(textOfTokens(50), true)},
+			budget:   estimateTokens("kept"),
+			kept:     []string{"kept"},
+		},
+		{
+			name:      "long message is cut",
+			messages:  []models.Message{message(long, false)},
+			budget:    models.AIContextMaxTokens,
+			kept:      []string{long[:models.AIContextMaxMessageRunes] + "…"},
+			truncated: true,
+		},
+		{
+			name:      "no budget",
+			messages:  []models.Message{message("one", false)},
+			budget:    0,
+			kept:      []string{},
+			truncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, truncated := fitBudget(chat, tt.messages, map[string]string{"owner": "owner"}, tt.budget)
+			texts := []string{}
+			for _, contextMsg := range kept {
+				texts = append(texts, contextMsg.Text)
+			}
+			if strings.Join(texts, "|") != strings.Join(tt.kept, "|") || len(texts) != len(tt.kept) {
+				t.Errorf("kept %q, want %q", texts, tt.kept)
+			}
+			if truncated != tt.truncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestContextMessageRoles(t *testing.T) {
+	chat := models.Chat{Type: models.GroupChatType, Owner: "owner", Admins: []string{"admin"}}
+	names := map[string]string{"owner": "olivia", "admin": "adam", "member": "mia"}
+	tests := []struct {
+		from        string
+		role        string
+		displayName string
+	}{
+		{from: "owner", role: models.RoleOwner, displayName: "olivia"},
+		{from: "admin", role: models.RoleAdmin, displayName: "adam"},
+		{from: "member", role: models.RoleMember, displayName: "mia"},
+		{from: models.MeeyokAI, role: models.AIRoleAssistant, displayName: models.MeeyokAI},
+		{from: models.SystemSender, role: models.AIRoleSystem, displayName: models.SystemSender},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			contextMsg, _ := contextMessage(chat, models.Message{ID: primitive.NewObjectID(), From: tt.from}, names)
+			if contextMsg.Role != tt.role || contextMsg.DisplayName != tt.displayName {
+				t.Errorf("role, name = %q, %q, want %q, %q", contextMsg.Role, contextMsg.DisplayName, tt.role, tt.displayName)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"slices"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// LeaveChat takes the user out of a group chat. An owner leaving hands the chat over to
+// the first admin, or the first member when there are no admins, and the last member
+// leaving deletes the chat.
+func (cs *chatService) LeaveChat(chatID string, userID string) (models.MemberRemoval, error) {
+	chat, err := cs.memberChat(chatID, userID)
+	if err != nil {
+		return models.MemberRemoval{}, err
+	}
+	if chat.Type != models.GroupChatType {
+		return models.MemberRemoval{}, models.ErrNotGroupChat
+	}
+
+	removal := models.MemberRemoval{
+		ChatID: chatID,
+		UserID: userID,
+		Members: slices.DeleteFunc(slices.Clone(chat.Users), func(member string) bool {
+			return member == userID
+		}),
+	}
+	if len(removal.Members) == 0 {
+		_, err := cs.DeleteChat(chatID)
+		return removal, err
+	}
+
+	if chat.Role(userID) == models.RoleOwner {
+		removal.NewOwner = successor(chat, removal.Members)
+	}
+	return removal, cs.removeMember(chatID, userID, removal.NewOwner)
+}
+
+// RemoveMember lets an owner or admin remove a member from a group chat,
+// admins can only be removed by the owner and the owner can only leave
+func (cs *chatService) RemoveMember(chatID string, actorID string, userID string) (models.MemberRemoval, error) {
+	if actorID == userID {
+		return cs.LeaveChat(chatID, userID)
+	}
+
+	chat, err := cs.Authorize(chatID, actorID, models.PermissionRemoveMembers)
+	if err != nil {
+		return models.MemberRemoval{}, err
+	}
+	if !slices.Contains(chat.Users, userID) {
+		return models.MemberRemoval{}, models.ErrMemberNotFound
+	}
+	switch chat.Role(userID) {
+	case models.RoleOwner:
+		return models.MemberRemoval{}, models.ErrPermissionDenied
+	case models.RoleAdmin:
+		if chat.Role(actorID) != models.RoleOwner {
+			return models.MemberRemoval{}, models.ErrPermissionDenied
+		}
+	}
+
+	removal := models.MemberRemoval{
+		ChatID:    chatID,
+		UserID:    userID,
+		RemovedBy: actorID,
+		Members: slices.DeleteFunc(slices.Clone(chat.Users), func(member string) bool {
+			return member == userID
+		}),
+	}
+	return removal, cs.removeMember(chatID, userID, "")
+}
+
+// removeMember updates both sides of the membership, the chat and the chats of the user.
+// A leaving owner hands the chat over to newOwnerID together with leaving it.
+func (cs *chatService) removeMember(chatID string, userID string, newOwnerID string) error {
+	if err := cs.chatRepo.RemoveUserFromChat(chatID, userID, newOwnerID); err != nil {
+		return err
+	}
+	if err := cs.userRepo.RemoveChatFromUser(userID, chatID); err != nil {
+		return err
+	}
+	cs.forgetUnreadCounts(chatID, []string{userID})
+	return nil
+}
+
+// successor picks the next owner of a chat among the members that stay
+func successor(chat models.Chat, members []string) string {
+	for _, admin := range chat.Admins {
+		if slices.Contains(members, admin) {
+			return admin
+		}
+	}
+	return members[0]
+}
@@ -0,0 +1,153 @@
+package chat
+
+import (
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// mentionPattern matches an @ that does not follow a word character, so e-mail addresses are left alone
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_.\-]+)`)
+
+// aiMentionPattern matches a mention of Meeyok AI, written the way people type it
+var aiMentionPattern = regexp.MustCompile(`(?i)@meeyok[ _]?ai`)
+
+// mentionToken is an @username found in a text, start and end bound the username without the @
+type mentionToken struct {
+	username   string
+	start, end int
+}
+
+// ParseMentions finds the @usernames in the text that belong to members of the chat,
+// anything that does not resolve to a member is plain text
+func (cs *chatService) ParseMentions(members []string, text string) []models.Mention {
+	tokens := mentionTokens(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var usernames []string
+	for _, token := range tokens {
+		if !slices.Contains(usernames, token.username) {
+			usernames = append(usernames, token.username)
+		}
+	}
+	users, err := cs.userRepo.GetUsersByUsernames(members, usernames)
+	if err != nil {
+		log.Printf("failed to resolve mentions: %v", err)
+		return nil
+	}
+	resolved := make(map[string]string, len(users))
+	for _, user := range users {
+		resolved[user.Username] = user.ID.Hex()
+	}
+	return resolveMentions(text, tokens, resolved)
+}
+
+// mentionTokens finds the @usernames in the text, up to models.MaxMentions distinct ones
+func mentionTokens(text string) []mentionToken {
+	var tokens []mentionToken
+	distinct := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		// A trailing dot ends the sentence, not the username
+		for end > start && text[end-1] == '.' {
+			end--
+		}
+		username := text[start:end]
+		if username == "" {
+			continue
+		}
+		if !distinct[username] {
+			if len(distinct) == models.MaxMentions {
+				continue
+			}
+			distinct[username] = true
+		}
+		tokens = append(tokens, mentionToken{username: username, start: start, end: end})
+	}
+	return tokens
+}
+
+// resolveMentions turns the tokens whose username resolved to a user ID into mentions
+func resolveMentions(text string, tokens []mentionToken, resolved map[string]string) []models.Mention {
+	var mentions []models.Mention
+	for _, token := range tokens {
+		userID, ok := resolved[token.username]
+		if !ok {
+			continue
+		}
+		// Include the @ in the entity
+		mentions = append(mentions, models.Mention{
+			UserID:   userID,
+			Username: token.username,
+			Offset:   utf16Len(text[:token.start-1]),
+			Length:   utf16Len(text[token.start-1 : token.end]),
+		})
+	}
+	return mentions
+}
+
+// MentionedUsers returns every user mentioned in the message once
+func MentionedUsers(mentions []models.Mention) []string {
+	var users []string
+	for _, mention := range mentions {
+		if !slices.Contains(users, mention.UserID) {
+			users = append(users, mention.UserID)
+		}
+	}
+	return users
+}
+
+// mentionSeparators set a mention apart from the rest of the sentence, like "hey @Meeyok AI, what's up"
+const mentionSeparators = ",:;"
+
+// sentenceEnds end a sentence right after a mention, like "what do you think @Meeyok AI?"
+const sentenceEnds = ".!?"
+
+// AIPrompt finds the mentions of Meeyok AI in the text, ok is false when it is not mentioned.
+// The prompt is the rest of the text without the separators that followed the mentions,
+// it is empty when the message is only the mention.
+func AIPrompt(text string) (prompt string, ok bool) {
+	var b strings.Builder
+	last := 0
+	for _, match := range aiMentionPattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		for end < len(text) && strings.IndexByte(mentionSeparators, text[end]) >= 0 {
+			end++
+		}
+		// Keep a single space where the mention was in the middle of the text
+		after, _ = utf8.DecodeRuneInString(text[end:])
+		if end == len(text) || after == ' ' || strings.ContainsRune(sentenceEnds, after) {
+			b.WriteString(strings.TrimRight(text[last:start], " "))
+		} else {
+			b.WriteString(text[last:start])
+		}
+		last = end
+		ok = true
+	}
+	if !ok {
+		return "", false
+	}
+	b.WriteString(text[last:])
+	return strings.TrimSpace(b.String()), true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
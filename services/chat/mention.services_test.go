@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+func TestAIPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		prompt string
+		ok     bool
+	}{
+		{name: "not mentioned", text: "hello there", prompt: "", ok: false},
+		{name: "only the mention", text: "@Meeyok AI", prompt: "", ok: true},
+		{name: "mention and separator only", text: "@Meeyok AI:", prompt: "", ok: true},
+		{name: "leading mention", text: "@Meeyok AI summarize this", prompt: "summarize this", ok: true},
+		{name: "leading mention with colon", text: "@MeeyokAI: summarize this", prompt: "summarize this", ok: true},
+		{name: "middle mention with comma", text: "hey @Meeyok AI, what's up", prompt: "hey what's up", ok: true},
+		{name: "middle mention", text: "please @meeyok_ai translate this", prompt: "please translate this", ok: true},
+		{name: "trailing mention", text: "what do you think @Meeyok AI", prompt: "what do you think", ok: true},
+		{name: "mention before question mark", text: "what do you think @Meeyok AI?", prompt: "what do you think?", ok: true},
+		{name: "separators after several mentions", text: "@Meeyok AI, hi; @MeeyokAI; bye", prompt: "hi; bye", ok: true},
+		{name: "part of a word", text: "@MeeyokAIbot hello", prompt: "", ok: false},
+		{name: "inside an e-mail address", text: "mail me at me@meeyokai", prompt: "", ok: false},
+		{name: "case insensitive", text: "@MEEYOK AI hello", prompt: "hello", ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, ok := AIPrompt(tt.text)
+			if prompt != tt.prompt || ok != tt.ok {
+				t.Errorf("AIPrompt(%q) = %q, %v, want %q, %v", tt.text, prompt, ok, tt.prompt, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMentionTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		usernames []string
+	}{
+		{name: "no mentions", text: "hello there", usernames: nil},
+		{name: "single mention", text: "hi @alice", usernames: []string{"alice"}},
+		{name: "trailing dot ends the sentence", text: "ask @bob.", usernames: []string{"bob"}},
+		{name: "dots inside the username", text: "@john.doe hi", usernames: []string{"john.doe"}},
+		{name: "e-mail address", text: "write to alice@example.com", usernames: nil},
+		{name: "repeated mention", text: "@alice and @alice", usernames: []string{"alice", "alice"}},
+		{name: "bare at sign", text: "meet @ noon", usernames: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var usernames []string
+			for _, token := range mentionTokens(tt.text) {
+				if got := tt.text[token.start:token.end]; got != token.username {
+					t.Errorf("token %q spans %q", token.username, got)
+				}
+				usernames = append(usernames, token.username)
+			}
+			if !reflect.DeepEqual(usernames, tt.usernames) {
+				t.Errorf("mentionTokens(%q) = %v, want %v", tt.text, usernames, tt.usernames)
+			}
+		})
+	}
+}
+
+func TestMentionTokensCap(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i <= models.MaxMentions; i++ {
+		fmt.Fprintf(&b, "@user%d ", i)
+	}
+	// Users that were mentioned already are still found past the cap
+	b.WriteString("@user0")
+
+	tokens := mentionTokens(b.String())
+	if len(tokens) != models.MaxMentions+1 {
+		t.Fatalf("found %d tokens, want %d", len(tokens), models.MaxMentions+1)
+	}
+	for _, token := range tokens {
+		if token.username == fmt.Sprintf("user%d", models.MaxMentions) {
+			t.Errorf("username past the cap was found")
+		}
+	}
+}
+
+func TestResolveMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		resolved map[string]string
+		mentions []models.Mention
+	}{
+		{
+			name:     "resolved mention",
+			text:     "hi @alice",
+			resolved: map[string]string{"alice": "id-alice"},
+			mentions: []models.Mention{{UserID: "id-alice", Username: "alice", Offset: 3, Length: 6}},
+		},
+		{
+			name:     "unresolved mention stays text",
+			text:     "hi @alice and @mallory",
+			resolved: map[string]string{"alice": "id-alice"},
+			mentions: []models.Mention{{UserID: "id-alice", Username: "alice", Offset: 3, Length: 6}},
+		},
+		{
+			name:     "offsets count UTF-16 code units",
+			text:     "😀 @alice",
+			resolved: map[string]string{"alice": "id-alice"},
+			mentions: []models.Mention{{UserID: "id-alice", Username: "alice", Offset: 3, Length: 6}},
+		},
+		{
+			name:     "nothing resolved",
+			text:     "hi @mallory",
+			resolved: map[string]string{},
+			mentions: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mentions := resolveMentions(tt.text, mentionTokens(tt.text), tt.resolved)
+			if !reflect.DeepEqual(mentions, tt.mentions) {
+				t.Errorf("resolveMentions(%q) = %+v, want %+v", tt.text, mentions, tt.mentions)
+			}
+		})
+	}
+}
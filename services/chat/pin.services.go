@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PinMessage pins a message of the chat, pinned reports whether it was not pinned before
+func (cs *chatService) PinMessage(chatID string, messageID string, userID string) (models.Pin, bool, error) {
+	if _, err := cs.Authorize(chatID, userID, models.PermissionPinMessages); err != nil {
+		return models.Pin{}, false, err
+	}
+	message, err := cs.messageRepo.GetMessage(chatID, messageID)
+	if err != nil {
+		return models.Pin{}, false, err
+	}
+	if message.Deleted {
+		return models.Pin{}, false, models.ErrMessageNotFound
+	}
+
+	pin := models.Pin{
+		MessageID: message.ID,
+		PinnedBy:  userID,
+		PinnedAt:  time.Now(),
+	}
+	pinned, err := cs.chatRepo.PinMessage(chatID, pin, models.MaxPinnedMessages)
+	if err != nil {
+		return models.Pin{}, false, err
+	}
+	return pin, pinned, nil
+}
+
+// UnpinMessage unpins a message of the chat, unpinned reports whether it was pinned
+func (cs *chatService) UnpinMessage(chatID string, messageID string, userID string) (bool, error) {
+	if _, err := cs.Authorize(chatID, userID, models.PermissionPinMessages); err != nil {
+		return false, err
+	}
+	objID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, models.ErrMessageNotFound
+	}
+	return cs.chatRepo.UnpinMessage(chatID, objID)
+}
+
+// GetPinnedMessages returns the pinned messages of the chat, the latest pin first.
+// Messages deleted for everyone or by the viewer for themselves are left out.
+func (cs *chatService) GetPinnedMessages(chatID string, viewer string) ([]models.PinnedMessage, error) {
+	chat, err := cs.chatRepo.GetChatByID(chatID)
+	if err != nil {
+		return nil, err
+	}
+	pinned := []models.PinnedMessage{}
+	if len(chat.Pins) == 0 {
+		return pinned, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(chat.Pins))
+	for _, pin := range chat.Pins {
+		ids = append(ids, pin.MessageID)
+	}
+	messages, err := cs.messageRepo.GetMessagesByIDs(chatID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Message, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+	}
+
+	for _, pin := range slices.Backward(chat.Pins) {
+		message, ok := byID[pin.MessageID]
+		if !ok || message.Deleted || slices.Contains(message.DeletedFor, viewer) {
+			continue
+		}
+		pinned = append(pinned, models.PinnedMessage{Pin: pin, Message: message})
+	}
+	return pinned, nil
+}
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetMessageTTL changes the lifetime of the messages sent into the chat from now on,
+// messages already sent keep the lifetime they were sent with
+func (cs *chatService) SetMessageTTL(chatID string, userID string, ttl time.Duration) (bool, error) {
+	chat, err := cs.Authorize(chatID, userID, models.PermissionSetRetention)
+	if err != nil {
+		return false, err
+	}
+	seconds := int64(ttl / time.Second)
+	if chat.MessageTTL == seconds {
+		return false, nil
+	}
+	if err := cs.chatRepo.SetMessageTTL(chatID, seconds); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExpireMessages deletes up to limit messages that expired at now, with their pins,
+// and returns them grouped by chat so their members and attachments can be dealt with
+func (cs *chatService) ExpireMessages(now time.Time, limit int) ([]models.ExpiredMessages, error) {
+	messages, err := cs.messageRepo.GetExpiredMessages(now, limit)
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+
+	chats := map[primitive.ObjectID]int{}
+	expired := []models.ExpiredMessages{}
+	chatMessageIDs := [][]primitive.ObjectID{}
+	for _, message := range messages {
+		i, ok := chats[message.ChatID]
+		if !ok {
+			i = len(expired)
+			chats[message.ChatID] = i
+			expired = append(expired, models.ExpiredMessages{ChatID: message.ChatID.Hex()})
+			chatMessageIDs = append(chatMessageIDs, nil)
+		}
+		expired[i].MessageIDs = append(expired[i].MessageIDs, message.ID.Hex())
+		expired[i].Attachments = append(expired[i].Attachments, message.Attachments...)
+		chatMessageIDs[i] = append(chatMessageIDs[i], message.ID)
+	}
+	if err := cs.messageRepo.DeleteMessages(messages); err != nil {
+		return nil, err
+	}
+
+	for i := range expired {
+		chatID := expired[i].ChatID
+		if err := cs.chatRepo.RemovePins(chatID, chatMessageIDs[i]); err != nil {
+			return nil, err
+		}
+		// The chat may be gone, its messages expire all the same
+		users, err := cs.chatRepo.GetChatUsers(chatID)
+		if err != nil && err != models.ErrChatNotFound {
+			return nil, err
+		}
+		expired[i].Members = users
+		cs.forgetUnreadCounts(chatID, users)
+	}
+	return expired, nil
+}
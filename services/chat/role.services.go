@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"slices"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// rolePermissions lists what every role of a group chat is allowed to do
+var rolePermissions = map[string][]models.Permission{
+	models.RoleOwner: {
+		models.PermissionRenameChat,
+		models.PermissionAddMembers,
+		models.PermissionRemoveMembers,
+		models.PermissionPromote,
+		models.PermissionDemote,
+		models.PermissionTransferOwner,
+		models.PermissionDeleteChat,
+		models.PermissionPinMessages,
+		models.PermissionSetRetention,
+		models.PermissionConfigureAI,
+	},
+	models.RoleAdmin: {
+		models.PermissionRenameChat,
+		models.PermissionAddMembers,
+		models.PermissionRemoveMembers,
+		models.PermissionPromote,
+		models.PermissionPinMessages,
+		models.PermissionSetRetention,
+		models.PermissionConfigureAI,
+	},
+	models.RoleMember: {},
+}
+
+// individualPermissions are shared by both members of an individual chat, and by the user of a chat with Meeyok
+var individualPermissions = []models.Permission{
+	models.PermissionRenameChat,
+	models.PermissionDeleteChat,
+	models.PermissionPinMessages,
+	models.PermissionSetRetention,
+	models.PermissionConfigureAI,
+}
+
+// Authorize returns the chat when the user is a member allowed to take the action,
+// models.ErrPermissionDenied when the role of the user does not allow it
+func (cs *chatService) Authorize(chatID string, userID string, permission models.Permission) (models.Chat, error) {
+	chat, err := cs.memberChat(chatID, userID)
+	if err != nil {
+		return models.Chat{}, err
+	}
+
+	if chat.Type != models.GroupChatType {
+		if !slices.Contains(individualPermissions, permission) {
+			return models.Chat{}, models.ErrNotGroupChat
+		}
+		return chat, nil
+	}
+	if !slices.Contains(rolePermissions[chat.Role(userID)], permission) {
+		return models.Chat{}, models.ErrPermissionDenied
+	}
+	return chat, nil
+}
+
+// memberChat returns the chat when the user is one of its members
+func (cs *chatService) memberChat(chatID string, userID string) (models.Chat, error) {
+	chat, err := cs.chatRepo.GetChatByID(chatID)
+	if err != nil {
+		return models.Chat{}, err
+	}
+	if !slices.Contains(chat.Users, userID) {
+		return models.Chat{}, models.ErrNotChatMember
+	}
+	return chat, nil
+}
+
+// Promote makes a member of a group chat an admin
+func (cs *chatService) Promote(chatID string, actorID string, userID string) error {
+	chat, err := cs.Authorize(chatID, actorID, models.PermissionPromote)
+	if err != nil {
+		return err
+	}
+	if err := checkRoleTarget(chat, userID); err != nil {
+		return err
+	}
+	if chat.Role(userID) != models.RoleMember {
+		return nil
+	}
+	return cs.chatRepo.SetAdmin(chatID, userID, true)
+}
+
+// Demote makes an admin of a group chat a member again, only the owner can demote
+// someone else but an admin can always step down
+func (cs *chatService) Demote(chatID string, actorID string, userID string) error {
+	var chat models.Chat
+	var err error
+	if actorID == userID {
+		chat, err = cs.memberChat(chatID, actorID)
+	} else {
+		chat, err = cs.Authorize(chatID, actorID, models.PermissionDemote)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkRoleTarget(chat, userID); err != nil {
+		return err
+	}
+	switch chat.Role(userID) {
+	case models.RoleOwner:
+		// The owner stops being the owner by handing the group over
+		return models.ErrPermissionDenied
+	case models.RoleMember:
+		return nil
+	}
+	return cs.chatRepo.SetAdmin(chatID, userID, false)
+}
+
+// TransferOwnership hands a group chat over to another member, the previous owner stays on as an admin
+func (cs *chatService) TransferOwnership(chatID string, ownerID string, newOwnerID string) error {
+	chat, err := cs.Authorize(chatID, ownerID, models.PermissionTransferOwner)
+	if err != nil {
+		return err
+	}
+	if err := checkRoleTarget(chat, newOwnerID); err != nil {
+		return err
+	}
+	if newOwnerID == ownerID {
+		return nil
+	}
+	return cs.chatRepo.TransferOwnership(chatID, ownerID, newOwnerID)
+}
+
+// checkRoleTarget makes sure roles are only given to members of a group chat
+func checkRoleTarget(chat models.Chat, userID string) error {
+	if chat.Type != models.GroupChatType {
+		return models.ErrNotGroupChat
+	}
+	if !slices.Contains(chat.Users, userID) {
+		return models.ErrMemberNotFound
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"log"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CountNewMessage updates the unread counters of the members of the chat for a new message,
+// and returns the new counter of every member. Sending a message marks the chat as read up to it.
+func (cs *chatService) CountNewMessage(message models.Message, members []string) map[string]int {
+	chatID := message.ChatID.Hex()
+	counts := make(map[string]int, len(members))
+	var others []string
+	for _, userID := range members {
+		if userID != message.From {
+			others = append(others, userID)
+			continue
+		}
+		if _, err := cs.readMarkerRepo.SetReadMarker(chatID, userID, message.ID); err != nil {
+			log.Printf("failed to move read marker of %s: %v", userID, err)
+			continue
+		}
+		if err := cs.unreadCacheRepo.SetUnreadCount(userID, chatID, 0); err != nil {
+			log.Printf("failed to cache unread count of %s: %v", userID, err)
+		}
+		counts[userID] = 0
+	}
+
+	cached, err := cs.unreadCacheRepo.IncrementUnreadCounts(chatID, others)
+	if err != nil {
+		log.Printf("failed to count unread message in chat %s: %v", chatID, err)
+		cached = map[string]int{}
+	}
+	for _, userID := range others {
+		count, ok := cached[userID]
+		if !ok {
+			// Not cached, the new message is already stored so counting picks it up
+			count, err = cs.countUnread(chatID, userID)
+			if err != nil {
+				log.Printf("failed to count unread messages of %s: %v", userID, err)
+				continue
+			}
+		}
+		counts[userID] = count
+	}
+	return counts
+}
+
+// MarkRead moves the read marker of the user up to the message and returns the unread counter after it
+func (cs *chatService) MarkRead(chatID string, userID string, messageID string) (int, string, error) {
+	messageObjID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return 0, "", models.ErrMessageNotFound
+	}
+	lastRead, err := cs.readMarkerRepo.SetReadMarker(chatID, userID, messageObjID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// Drop the cached counter before counting, a message arriving meanwhile then counts
+	// itself from the messages instead of being lost when the counter is overwritten
+	if err := cs.unreadCacheRepo.DeleteUnreadCounts(chatID, []string{userID}); err != nil {
+		return 0, "", err
+	}
+	count, err := cs.messageRepo.CountUnread(chatID, userID, lastRead)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := cs.unreadCacheRepo.FillUnreadCount(userID, chatID, count); err != nil {
+		log.Printf("failed to cache unread count of %s: %v", userID, err)
+	}
+	return count, lastRead.Hex(), nil
+}
+
+// MarkMessages records a receipt of the user on the messages up to upToMessageID. Messages up to
+// the read marker were read and delivered already, so the receipt starts after it.
+func (cs *chatService) MarkMessages(chatID string, userID string, upToMessageID string, status string) ([]models.Message, error) {
+	lastRead, err := cs.readMarkerRepo.GetReadMarker(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return cs.messageRepo.MarkMessages(chatID, userID, lastRead, upToMessageID, status)
+}
+
+// countUnread counts the unread messages of the user from the messages and caches the counter
+func (cs *chatService) countUnread(chatID string, userID string) (int, error) {
+	lastRead, err := cs.readMarkerRepo.GetReadMarker(chatID, userID)
+	if err != nil {
+		return 0, err
+	}
+	count, err := cs.messageRepo.CountUnread(chatID, userID, lastRead)
+	if err != nil {
+		return 0, err
+	}
+	if err := cs.unreadCacheRepo.FillUnreadCount(userID, chatID, count); err != nil {
+		log.Printf("failed to cache unread count of %s: %v", userID, err)
+	}
+	return count, nil
+}
+
+// fillUnreadCounts sets the unread counter and read marker of the user on every chat,
+// counters missing from the cache are counted from the messages
+func (cs *chatService) fillUnreadCounts(userID string, chats []models.Chat) error {
+	chatIDs := make([]string, 0, len(chats))
+	for _, chat := range chats {
+		chatIDs = append(chatIDs, chat.ID.Hex())
+	}
+
+	cached, err := cs.unreadCacheRepo.GetUnreadCounts(userID, chatIDs)
+	if err != nil {
+		log.Printf("failed to read unread counts of %s: %v", userID, err)
+		cached = map[string]int{}
+	}
+	markers, err := cs.readMarkerRepo.GetReadMarkers(userID, chatIDs)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, chatID := range chatIDs {
+		if _, ok := cached[chatID]; !ok {
+			missing = append(missing, chatID)
+		}
+	}
+	counted := map[string]int{}
+	if len(missing) > 0 {
+		counted, err = cs.messageRepo.CountUnreadByChat(userID, missing, markers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range chats {
+		chatID := chatIDs[i]
+		if lastRead, ok := markers[chatID]; ok {
+			chats[i].LastReadMessageID = lastRead.Hex()
+		}
+
+		count, ok := cached[chatID]
+		if !ok {
+			count = counted[chatID]
+			if err := cs.unreadCacheRepo.FillUnreadCount(userID, chatID, count); err != nil {
+				log.Printf("failed to cache unread count of %s: %v", userID, err)
+			}
+		}
+		chats[i].UnreadCount = count
+	}
+	return nil
+}
+
+// forgetUnreadCounts drops the cached counters of the users in the chat so they are counted again
+func (cs *chatService) forgetUnreadCounts(chatID string, userIDs []string) {
+	if err := cs.unreadCacheRepo.DeleteUnreadCounts(chatID, userIDs); err != nil {
+		log.Printf("failed to drop unread counts of chat %s: %v", chatID, err)
+	}
+}
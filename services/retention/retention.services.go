@@ -0,0 +1,71 @@
+package retention
+
+import (
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/services/attachment"
+	"github.com/Meeyok-Chat/backend/services/chat"
+	Websocket "github.com/Meeyok-Chat/backend/services/websocket"
+)
+
+const (
+	// sweepInterval is how often expired messages are looked for, clients hide them on time using their expiresAt
+	sweepInterval = 30 * time.Second
+	// sweepBatch is how many messages are expired at once
+	sweepBatch = 500
+)
+
+type retentionService struct {
+	chatService       chat.ChatService
+	attachmentService attachment.AttachmentService
+	websocketManager  Websocket.ManagerService
+}
+
+type RetentionService interface {
+	Sweep()
+}
+
+func NewRetentionService(chatService chat.ChatService, attachmentService attachment.AttachmentService, websocketManager Websocket.ManagerService) RetentionService {
+	return &retentionService{
+		chatService:       chatService,
+		attachmentService: attachmentService,
+		websocketManager:  websocketManager,
+	}
+}
+
+// Sweep deletes the messages of chats with disappearing messages once they expire,
+// it runs for the lifetime of the server
+func (rs *retentionService) Sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rs.sweepExpired()
+	}
+}
+
+// sweepExpired expires batches of messages until none is left
+func (rs *retentionService) sweepExpired() {
+	for {
+		expired, err := rs.chatService.ExpireMessages(time.Now(), sweepBatch)
+		if err != nil {
+			log.Printf("Failed to expire messages: %v", err)
+			return
+		}
+
+		count := 0
+		for _, chatExpired := range expired {
+			count += len(chatExpired.MessageIDs)
+			if err := rs.attachmentService.DeleteAttachments(chatExpired.Attachments); err != nil {
+				log.Printf("Failed to delete attachments of expired messages in chat %s: %v", chatExpired.ChatID, err)
+			}
+			if err := rs.websocketManager.SendMessagesExpiredHandler(chatExpired); err != nil {
+				log.Printf("Failed to send expired messages of chat %s: %v", chatExpired.ChatID, err)
+			}
+		}
+		if count < sweepBatch {
+			return
+		}
+	}
+}
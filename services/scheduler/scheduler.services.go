@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Meeyok-Chat/backend/dtos"
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/services/chat"
+	Websocket "github.com/Meeyok-Chat/backend/services/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// dispatchInterval is how often the dispatcher looks for due messages
+	dispatchInterval = 5 * time.Second
+	// claimLease is how long an instance has to send a message it claimed before another one takes over
+	claimLease = time.Minute
+)
+
+type schedulerService struct {
+	scheduledRepo    database.ScheduledMessageRepo
+	chatService      chat.ChatService
+	websocketManager Websocket.ManagerService
+}
+
+type SchedulerService interface {
+	ScheduleMessage(userID string, req dtos.ScheduleMessageRequest) (models.ScheduledMessage, error)
+	GetScheduledMessages(userID string, chatID string) ([]models.ScheduledMessage, error)
+	EditScheduledMessage(id string, userID string, req dtos.EditScheduledMessageRequest) (models.ScheduledMessage, error)
+	CancelScheduledMessage(id string, userID string) error
+	Dispatch()
+}
+
+func NewSchedulerService(scheduledRepo database.ScheduledMessageRepo, chatService chat.ChatService, websocketManager Websocket.ManagerService) SchedulerService {
+	return &schedulerService{
+		scheduledRepo:    scheduledRepo,
+		chatService:      chatService,
+		websocketManager: websocketManager,
+	}
+}
+
+// ScheduleMessage stores a message of the user to be sent into the chat at req.SendAt
+func (ss *schedulerService) ScheduleMessage(userID string, req dtos.ScheduleMessageRequest) (models.ScheduledMessage, error) {
+	if err := ss.chatService.CheckMember(req.ChatID, userID); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	chatID, err := primitive.ObjectIDFromHex(req.ChatID)
+	if err != nil {
+		return models.ScheduledMessage{}, models.ErrChatNotFound
+	}
+	if len(req.Attachments) > models.MaxMessageAttachments {
+		return models.ScheduledMessage{}, models.ErrTooManyAttachments
+	}
+	attachments := make([]primitive.ObjectID, 0, len(req.Attachments))
+	for _, id := range req.Attachments {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return models.ScheduledMessage{}, models.ErrAttachmentNotFound
+		}
+		attachments = append(attachments, objID)
+	}
+
+	now := time.Now()
+	message := models.ScheduledMessage{
+		ID:          primitive.NewObjectID(),
+		ChatID:      chatID,
+		From:        userID,
+		Message:     req.Message,
+		ReplyTo:     req.ReplyTo,
+		Attachments: attachments,
+		SendAt:      req.SendAt,
+		Status:      models.ScheduledPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := validate(message, now); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	if err := ss.scheduledRepo.InsertScheduledMessage(message); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	return message, nil
+}
+
+// GetScheduledMessages lists the messages the user scheduled that were not sent yet, failed ones included
+func (ss *schedulerService) GetScheduledMessages(userID string, chatID string) ([]models.ScheduledMessage, error) {
+	if chatID != "" {
+		if err := ss.chatService.CheckMember(chatID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return ss.scheduledRepo.GetScheduledMessages(userID, chatID)
+}
+
+// EditScheduledMessage changes the text or the time of a pending message
+func (ss *schedulerService) EditScheduledMessage(id string, userID string, req dtos.EditScheduledMessageRequest) (models.ScheduledMessage, error) {
+	message, err := ss.scheduledRepo.GetScheduledMessage(id, userID)
+	if err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	if message.Status != models.ScheduledPending {
+		return models.ScheduledMessage{}, models.ErrScheduledNotFound
+	}
+
+	if req.Message != nil {
+		message.Message = *req.Message
+	}
+	if req.SendAt != nil {
+		message.SendAt = *req.SendAt
+	}
+	if err := validate(message, time.Now()); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+	return ss.scheduledRepo.UpdateScheduledMessage(message)
+}
+
+// CancelScheduledMessage drops a pending message before it is sent, or dismisses a failed one
+func (ss *schedulerService) CancelScheduledMessage(id string, userID string) error {
+	return ss.scheduledRepo.DeleteScheduledMessage(id, userID)
+}
+
+// Dispatch sends the scheduled messages as they come due, it runs for the lifetime of the server.
+// Every instance can run it, a message is only claimed by one of them.
+func (ss *schedulerService) Dispatch() {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ss.dispatchDue()
+	}
+}
+
+// dispatchDue sends every message that is due right now
+func (ss *schedulerService) dispatchDue() {
+	for {
+		message, ok, err := ss.scheduledRepo.ClaimDueMessage(time.Now(), claimLease)
+		if err != nil {
+			log.Printf("Failed to claim scheduled message: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		// Instances that stopped responding while sending it used up the attempts
+		if message.Attempts >= models.MaxScheduledAttempts {
+			if err := ss.scheduledRepo.FailScheduledMessage(message.ID, message.Error, nil); err != nil {
+				log.Printf("Failed to record failure of scheduled message %s: %v", message.ID.Hex(), err)
+			}
+			continue
+		}
+		ss.send(message)
+	}
+}
+
+// send goes through the same path as a message sent over the websocket,
+// errors caused by the message itself are not retried. Every attempt sends the
+// message with the same ID, so one that was stored before a crash is not sent twice.
+func (ss *schedulerService) send(message models.ScheduledMessage) {
+	attachments := make([]models.Attachment, 0, len(message.Attachments))
+	for _, id := range message.Attachments {
+		attachments = append(attachments, models.Attachment{ID: id})
+	}
+	messageID := ""
+	if message.MessageID != nil {
+		messageID = message.MessageID.Hex()
+	}
+
+	err := ss.websocketManager.SendUserMessage(models.SendMessageEvent{
+		ID:          messageID,
+		ChatID:      message.ChatID.Hex(),
+		Message:     message.Message,
+		From:        message.From,
+		ReplyTo:     message.ReplyTo,
+		Attachments: attachments,
+	})
+	if err == nil {
+		if err := ss.scheduledRepo.CompleteScheduledMessage(message.ID); err != nil {
+			log.Printf("Failed to complete scheduled message %s: %v", message.ID.Hex(), err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if models.ErrorStatus(err, http.StatusInternalServerError) >= http.StatusInternalServerError &&
+		message.Attempts+1 < models.MaxScheduledAttempts {
+		next := time.Now().Add(models.ScheduledRetryBackoff << message.Attempts)
+		retryAt = &next
+	}
+	log.Printf("Failed to send scheduled message %s: %v", message.ID.Hex(), err)
+	if err := ss.scheduledRepo.FailScheduledMessage(message.ID, err.Error(), retryAt); err != nil {
+		log.Printf("Failed to record failure of scheduled message %s: %v", message.ID.Hex(), err)
+	}
+}
+
+func validate(message models.ScheduledMessage, now time.Time) error {
+	if strings.TrimSpace(message.Message) == "" && len(message.Attachments) == 0 {
+		return models.ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(message.Message) > models.MaxMessageRunes {
+		return models.ErrMessageTooLong
+	}
+	if !message.SendAt.After(now) || message.SendAt.After(now.Add(models.MaxScheduleAhead)) {
+		return models.ErrInvalidSchedule
+	}
+	return nil
+}
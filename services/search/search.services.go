@@ -0,0 +1,77 @@
+package search
+
+import (
+	"errors"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/database"
+	"github.com/Meeyok-Chat/backend/repository/search"
+	"github.com/Meeyok-Chat/backend/services/chat"
+)
+
+type searchService struct {
+	searchRepo  search.SearchRepo
+	chatRepo    database.ChatRepo
+	chatService chat.ChatService
+}
+
+type SearchService interface {
+	Search(userID string, chatID string, query models.SearchQuery) (models.SearchResult, error)
+}
+
+func NewSearchService(searchRepo search.SearchRepo, chatRepo database.ChatRepo, chatService chat.ChatService) SearchService {
+	return &searchService{
+		searchRepo:  searchRepo,
+		chatRepo:    chatRepo,
+		chatService: chatService,
+	}
+}
+
+// Search looks through the chats of the user only, or through a single chat of the user when chatID is set
+func (ss *searchService) Search(userID string, chatID string, query models.SearchQuery) (models.SearchResult, error) {
+	if query.Text == "" {
+		return models.SearchResult{}, errors.New("search text is required")
+	}
+
+	query.Viewer = userID
+	if chatID != "" {
+		if err := ss.chatService.CheckMember(chatID, userID); err != nil {
+			return models.SearchResult{}, err
+		}
+		query.ChatIDs = []string{chatID}
+	} else {
+		chatIDs, err := ss.chatRepo.GetUserChatIDs(userID)
+		if err != nil {
+			return models.SearchResult{}, err
+		}
+		query.ChatIDs = chatIDs
+	}
+
+	result := models.SearchResult{
+		Chats:    []models.ChatSearchHit{},
+		Messages: []models.MessageSearchHit{},
+	}
+	if len(query.ChatIDs) == 0 {
+		return result, nil
+	}
+
+	messages, hasMore, err := ss.searchRepo.SearchMessages(query)
+	if err != nil {
+		return models.SearchResult{}, err
+	}
+	result.Messages = messages
+	result.HasMore = hasMore
+	if hasMore && len(messages) > 0 {
+		result.NextCursor = messages[len(messages)-1].Message.ID.Hex()
+	}
+
+	// Chat names only match on the first page, and never when looking for a sender
+	if query.Before == "" && query.From == "" {
+		chats, err := ss.searchRepo.SearchChats(query)
+		if err != nil {
+			return models.SearchResult{}, err
+		}
+		result.Chats = chats
+	}
+	return result, nil
+}
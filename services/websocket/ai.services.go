@@ -0,0 +1,175 @@
+package Websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/services/chat"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SendAIReplyDelta fans a part of a reply of Meeyok AI that is still being written out to the members
+// of the chat. Deltas are not replayed, a resuming client gets the whole reply once it is done.
+// Only failing to buffer the delta is an error, the whole reply cannot be assembled without it.
+func (ms *managerService) SendAIReplyDelta(chatID string, correlationID string, seq int, delta string) error {
+	if err := ms.replyCacheRepo.AppendDelta(correlationID, seq, delta); err != nil {
+		return fmt.Errorf("failed to buffer reply delta: %v", err)
+	}
+
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		log.Printf("failed to get chat of reply delta %s: %v", correlationID, err)
+		return nil
+	}
+	data, err := json.Marshal(models.AIReplyDeltaEvent{
+		ChatID:        chatID,
+		CorrelationID: correlationID,
+		Seq:           seq,
+		Delta:         delta,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	if err := ms.publishEphemeral(users, models.Event{Type: models.EventAIReplyDelta, Payload: data}); err != nil {
+		log.Printf("failed to send reply delta %s: %v", correlationID, err)
+	}
+	return nil
+}
+
+// SendAIReplyDone stores the whole reply of Meeyok AI as a single message and ends its stream,
+// the reply is assembled from its deltas when message is empty. It fails with models.ErrReplyIncomplete
+// until all the deltas arrived, so the done part is delivered again. A request is only answered once,
+// by whichever of its attempts claims it first, replies delivered after that are dropped.
+func (ms *managerService) SendAIReplyDone(chatID string, correlationID string, message string, deltas int) error {
+	requestID := models.AIRequestID(correlationID)
+	request, err := ms.aiRequestRepo.GetAIRequest(requestID)
+	tracked := err == nil
+	if err != nil && !errors.Is(err, models.ErrAIRequestNotFound) {
+		return fmt.Errorf("failed to get AI request: %v", err)
+	}
+	if tracked && request.Status == models.AIRequestAnswered {
+		log.Printf("dropping reply to AI request %s, it was answered already", correlationID)
+		return ms.replyCacheRepo.DeleteReply(correlationID)
+	}
+
+	if message == "" {
+		reply, err := ms.replyCacheRepo.GetReply(correlationID, deltas)
+		if err != nil {
+			return fmt.Errorf("failed to assemble reply: %w", err)
+		}
+		message = reply
+	}
+
+	if tracked {
+		claimed, err := ms.aiRequestRepo.SetAIRequestStatus(requestID, models.AIRequestAnswered, "",
+			models.AIRequestPending, models.AIRequestTimedOut, models.AIRequestFailed)
+		if err != nil {
+			return fmt.Errorf("failed to claim AI request: %v", err)
+		}
+		if !claimed {
+			log.Printf("dropping reply to AI request %s, it was answered already", correlationID)
+			return ms.replyCacheRepo.DeleteReply(correlationID)
+		}
+	}
+
+	chatevent := models.SendMessageEvent{
+		ChatID:        chatID,
+		Message:       message,
+		From:          models.MeeyokAI,
+		CorrelationID: correlationID,
+	}
+	if err := ms.sendMessage(&chatevent); err != nil {
+		// Let the reply delivered again claim the request
+		if tracked {
+			if _, err := ms.aiRequestRepo.SetAIRequestStatus(requestID, request.Status, "", models.AIRequestAnswered); err != nil {
+				log.Printf("failed to release AI request %s: %v", requestID, err)
+			}
+		}
+		return err
+	}
+	if err := ms.replyCacheRepo.DeleteReply(correlationID); err != nil {
+		log.Printf("failed to drop deltas of reply %s: %v", correlationID, err)
+	}
+	if tracked {
+		messageID, _ := primitive.ObjectIDFromHex(chatevent.ID)
+		if err := ms.aiRequestRepo.MarkAIRequestAnswered(requestID, messageID); err != nil {
+			log.Printf("failed to mark AI request %s answered: %v", requestID, err)
+		}
+	}
+
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+	data, err := json.Marshal(models.AIReplyDoneEvent{
+		ChatID:        chatID,
+		CorrelationID: correlationID,
+		MessageID:     chatevent.ID,
+		Message:       chatevent.Message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(users, models.Event{Type: models.EventAIReplyDone, Payload: data})
+}
+
+// SendAISettingsChangedHandler tells the members of the chat whether Meeyok AI replies in it and the
+// persona it follows, turning it on or off is written into the history of the chat as a system message
+func (ms *managerService) SendAISettingsChangedHandler(chat models.Chat, userID string, toggled bool) error {
+	data, err := json.Marshal(models.AISettingsChangedEvent{
+		ChatID:    chat.ID.Hex(),
+		AIEnabled: chat.AIEnabled(),
+		AIPersona: chat.AIPersona,
+		By:        userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	if err := ms.publish(chat.Users, models.Event{Type: models.EventAISettingsChanged, Payload: data}); err != nil {
+		return err
+	}
+	if !toggled {
+		return nil
+	}
+
+	text := ms.displayName(userID) + " turned off " + models.MeeyokAI
+	if chat.AIEnabled() {
+		text = ms.displayName(userID) + " turned on " + models.MeeyokAI
+	}
+	return ms.SendSystemMessage(chat.ID.Hex(), text)
+}
+
+// aiPrompt decides whether a message asks Meeyok AI for a reply and returns what it asks.
+// Every message of the user in a chat with Meeyok does, elsewhere the AI has to be mentioned.
+// A message that asks nothing, like a bare mention or only attachments, does not.
+// Messages of the AI and of the server never do, so the AI cannot trigger itself.
+func aiPrompt(target models.Chat, from string, text string) (string, bool) {
+	if from == models.MeeyokAI || from == models.SystemSender || !target.AIEnabled() {
+		return "", false
+	}
+	prompt, mentioned := chat.AIPrompt(text)
+	if !mentioned && target.Type == models.MeeyokChatType {
+		prompt, mentioned = strings.TrimSpace(text), true
+	}
+	return prompt, mentioned && prompt != ""
+}
+
+// checkAIRateLimit keeps a user from asking Meeyok AI more than AIRequestRateLimit times within
+// AIRequestRateWindow. The requests are counted in the database, so the limit holds across instances.
+func (ms *managerService) checkAIRateLimit(userID string) error {
+	count, err := ms.aiRequestRepo.CountAIRequestsSince(userID, time.Now().Add(-models.AIRequestRateWindow))
+	if err != nil {
+		// The AI stays available when the requests cannot be counted
+		log.Printf("failed to count the AI requests of user %s: %v", userID, err)
+		return nil
+	}
+	if count >= models.AIRequestRateLimit {
+		return models.ErrAIRateLimited
+	}
+	return nil
+}
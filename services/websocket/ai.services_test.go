@@ -0,0 +1,41 @@
+package Websocket
+
+import (
+	"testing"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+func TestAIPrompt(t *testing.T) {
+	group := models.Chat{Type: models.GroupChatType}
+	disabled := models.Chat{Type: models.GroupChatType, AIDisabled: true}
+	meeyok := models.Chat{Type: models.MeeyokChatType}
+
+	tests := []struct {
+		name   string
+		chat   models.Chat
+		from   string
+		text   string
+		prompt string
+		ok     bool
+	}{
+		{name: "group without mention", chat: group, from: "user", text: "hello", ok: false},
+		{name: "group with mention", chat: group, from: "user", text: "@Meeyok AI hello", prompt: "hello", ok: true},
+		{name: "group with bare mention", chat: group, from: "user", text: "@Meeyok AI", ok: false},
+		{name: "AI turned off", chat: disabled, from: "user", text: "@Meeyok AI hello", ok: false},
+		{name: "meeyok chat without mention", chat: meeyok, from: "user", text: "  hello  ", prompt: "hello", ok: true},
+		{name: "meeyok chat with mention", chat: meeyok, from: "user", text: "@Meeyok AI, hello", prompt: "hello", ok: true},
+		{name: "meeyok chat with bare mention", chat: meeyok, from: "user", text: "@Meeyok AI", ok: false},
+		{name: "meeyok chat with only attachments", chat: meeyok, from: "user", text: "", ok: false},
+		{name: "AI never triggers itself", chat: meeyok, from: models.MeeyokAI, text: "@Meeyok AI hello", ok: false},
+		{name: "system messages never trigger", chat: meeyok, from: models.SystemSender, text: "hello", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, ok := aiPrompt(tt.chat, tt.from, tt.text)
+			if prompt != tt.prompt || ok != tt.ok {
+				t.Errorf("aiPrompt(%q) = %q, %v, want %q, %v", tt.text, prompt, ok, tt.prompt, tt.ok)
+			}
+		})
+	}
+}
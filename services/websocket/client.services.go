@@ -16,6 +16,11 @@ var (
 	// Because that can make decimals, so instead *9 / 10 to get 90%
 	// The reason why it has to be less than PingRequency is becuase otherwise it will send a new Ping before getting response
 	pingInterval = (pongWait * 9) / 10
+	// egressBufferSize is how many events may wait for a slow client before new ones are dropped
+	egressBufferSize = 256
+	// maxEventSize is the largest event a client can send, enough for a message of models.MaxMessageRunes
+	// runes escaped in the JSON with its attachments, reply and ID. Larger events close the connection.
+	maxEventSize int64 = 64 << 10
 )
 
 type clientService struct {
@@ -36,7 +41,7 @@ func NewClientService(user models.User, conn *websocket.Conn, manager ManagerSer
 			User:       user,
 			ClientData: models.ClientData{},
 			Connection: conn,
-			Egress:     make(chan models.Event),
+			Egress:     make(chan models.Event, egressBufferSize),
 		},
 		manager: manager,
 	}
@@ -52,7 +57,7 @@ func (cs *clientService) ReadMessages() {
 		cs.manager.RemoveClient(cs.client)
 	}()
 	// Set Max Size of Messages in Bytes
-	cs.client.Connection.SetReadLimit(512)
+	cs.client.Connection.SetReadLimit(maxEventSize)
 	// Configure Wait time for Pong response, use Current time + pongWait
 	// This has to be done here to set the first initial timer.
 	if err := cs.client.Connection.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
@@ -6,58 +6,137 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Meeyok-Chat/backend/models"
+	"github.com/Meeyok-Chat/backend/repository/broadcast"
+	"github.com/Meeyok-Chat/backend/repository/cache"
 	"github.com/Meeyok-Chat/backend/repository/database"
 	"github.com/Meeyok-Chat/backend/repository/queue/queuePublisher"
+	"github.com/Meeyok-Chat/backend/repository/replay"
+	"github.com/Meeyok-Chat/backend/repository/ticket"
+	"github.com/Meeyok-Chat/backend/services/attachment"
+	"github.com/Meeyok-Chat/backend/services/chat"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type managerService struct {
-	clients  models.ClientList
-	chatRepo database.ChatRepo
-	userRepo database.UserRepo
+	clients     models.ClientList
+	chatRepo    database.ChatRepo
+	messageRepo database.MessageRepo
+	userRepo    database.UserRepo
+	// aiRequestRepo tracks every request to the AI worker until it is answered
+	aiRequestRepo database.AIRequestRepo
+	// chatService authorizes every chat event of a client
+	chatService chat.ChatService
+	// attachmentService binds uploaded attachments to the messages they are sent with
+	attachmentService attachment.AttachmentService
 
 	queuePublisher queuePublisher.QueuePublisher
+	// broadcastRepo carries events to the instances holding the recipients' sockets
+	broadcastRepo broadcast.BroadcastRepo
+	// replayRepo numbers the events of each user and buffers them for resuming clients
+	replayRepo replay.ReplayRepo
+	// ticketRepo binds websocket upgrades to the user authenticated on /ws/init
+	ticketRepo ticket.TicketRepo
+	// replyCacheRepo assembles the streamed replies of Meeyok AI
+	replyCacheRepo cache.ReplyCacheRepo
 	// Using a syncMutex here to be able to lcok state before editing clients
 	// Could also use Channels to block
 	sync.RWMutex
 	// handlers are functions that are used to handle Events
 	handlers map[string]models.EventHandler
+
+	// typing holds the expiry timer of every user currently typing in a chat
+	typing     map[typingKey]*time.Timer
+	typingLock sync.Mutex
+
+	// resuming holds the live events of clients still being sent what they missed,
+	// they are delivered once the replay is done so nothing arrives out of order
+	resuming     map[*models.Client][]models.Event
+	resumingLock sync.Mutex
 }
 
 // Manager is used to hold references to all Clients Registered, and Broadcasting etc
 type ManagerService interface {
-	AddClient(conn *websocket.Conn, c *gin.Context, userID string)
+	AddClient(conn *websocket.Conn, c *gin.Context, userID string, lastSeq *uint64)
 	RemoveClient(client *models.Client)
 	RouteEvent(event models.Event, c *models.Client) error
 	CheckOldClient(userID string) error
+	IssueTicket(userID string) (string, error)
+	RedeemTicket(ticket string) (string, error)
 	GetClients() []models.User
 
 	SendMessageHandler(event models.Event, c *models.Client) error
+	SendUserMessage(chatevent models.SendMessageEvent) error
+	SendBotMessage(chatID string, message string) error
+	SendAIReplyDelta(chatID string, correlationID string, seq int, delta string) error
+	SendAIReplyDone(chatID string, correlationID string, message string, deltas int) error
+	SendSystemMessage(chatID string, message string) error
+	MarkDeliveredHandler(event models.Event, c *models.Client) error
+	MarkReadHandler(event models.Event, c *models.Client) error
+	TypingStartHandler(event models.Event, c *models.Client) error
+	TypingStopHandler(event models.Event, c *models.Client) error
+	EditMessageHandler(event models.Event, c *models.Client) error
+	DeleteMessageHandler(event models.Event, c *models.Client) error
+	AddReactionHandler(event models.Event, c *models.Client) error
+	RemoveReactionHandler(event models.Event, c *models.Client) error
 	SendUserStatusHandler(userId string, eventType string) error
 	SendNewGroupHandler(chatID string) error
+	SendRoleChangedHandler(chatID string, userID string, role string) error
+	SendMemberRemovedHandler(removal models.MemberRemoval) error
+	SendChatDeletedHandler(chatID string, members []string, userID string) error
+	SendPinnedChangedHandler(chatID string, messageID string, userID string, pinned bool) error
+	SendRetentionChangedHandler(chatID string, userID string, ttl time.Duration) error
+	SendAISettingsChangedHandler(chat models.Chat, userID string, toggled bool) error
+	SendMessagesExpiredHandler(expired models.ExpiredMessages) error
+	SendMessageEditedHandler(message models.Message) error
+	SendMessageDeletedHandler(message models.Message, userID string, forEveryone bool) error
+	SendReactionChangedHandler(message models.Message, userID string, emoji string, added bool) error
 }
 
 // NewManager is used to initalize all the values inside the manager
-func NewManagerService(queuePublisher queuePublisher.QueuePublisher, chatRepo database.ChatRepo, userRepo database.UserRepo) ManagerService {
+func NewManagerService(queuePublisher queuePublisher.QueuePublisher, broadcastRepo broadcast.BroadcastRepo, replayRepo replay.ReplayRepo, ticketRepo ticket.TicketRepo, replyCacheRepo cache.ReplyCacheRepo, chatService chat.ChatService, attachmentService attachment.AttachmentService, chatRepo database.ChatRepo, messageRepo database.MessageRepo, userRepo database.UserRepo, aiRequestRepo database.AIRequestRepo) ManagerService {
 	m := &managerService{
-		clients:        make(models.ClientList),
-		chatRepo:       chatRepo,
-		userRepo:       userRepo,
-		queuePublisher: queuePublisher,
-		handlers:       make(map[string]models.EventHandler),
+		clients:           make(models.ClientList),
+		chatRepo:          chatRepo,
+		messageRepo:       messageRepo,
+		userRepo:          userRepo,
+		aiRequestRepo:     aiRequestRepo,
+		chatService:       chatService,
+		attachmentService: attachmentService,
+		queuePublisher:    queuePublisher,
+		broadcastRepo:     broadcastRepo,
+		replayRepo:        replayRepo,
+		ticketRepo:        ticketRepo,
+		replyCacheRepo:    replyCacheRepo,
+		handlers:          make(map[string]models.EventHandler),
+		typing:            make(map[typingKey]*time.Timer),
+		resuming:          make(map[*models.Client][]models.Event),
 	}
 	m.setupEventHandlers()
+	broadcastRepo.Subscribe(m.deliverBroadcast)
 	return m
 }
 
 // setupEventHandlers configures and adds all handlers
 func (ms *managerService) setupEventHandlers() {
 	ms.handlers[models.EventSendMessage] = ms.SendMessageHandler
+	ms.handlers[models.EventMarkDelivered] = ms.MarkDeliveredHandler
+	ms.handlers[models.EventMarkRead] = ms.MarkReadHandler
+	ms.handlers[models.EventTypingStart] = ms.TypingStartHandler
+	ms.handlers[models.EventTypingStop] = ms.TypingStopHandler
+	ms.handlers[models.EventEditMessage] = ms.EditMessageHandler
+	ms.handlers[models.EventDeleteMessage] = ms.DeleteMessageHandler
+	ms.handlers[models.EventAddReaction] = ms.AddReactionHandler
+	ms.handlers[models.EventRemoveReaction] = ms.RemoveReactionHandler
 }
 
 // routeEvent is used to make sure the correct event goes into the correct handler
@@ -66,11 +145,38 @@ func (ms *managerService) RouteEvent(event models.Event, c *models.Client) error
 	if handler, ok := ms.handlers[event.Type]; ok {
 		// Execute the handler and return any err
 		if err := handler(event, c); err != nil {
+			ms.sendError(c, err)
 			return err
 		}
 		return nil
 	} else {
-		return errors.New("this event type is not supported")
+		err := errors.New("this event type is not supported")
+		ms.sendError(c, err)
+		return err
+	}
+}
+
+// sendError tells the client why its event was rejected, using the HTTP status codes of the REST API
+func (ms *managerService) sendError(c *models.Client, err error) {
+	code := models.ErrorStatus(err, http.StatusBadRequest)
+	data, marshalErr := json.Marshal(models.HTTPError{Message: err.Error(), Code: code})
+	if marshalErr != nil {
+		log.Println(marshalErr)
+		return
+	}
+	ms.sendToClient(c, models.Event{Type: models.EventError, Payload: data})
+}
+
+// sendUserError tells every client of the user why something it asked for did not happen
+func (ms *managerService) sendUserError(userID string, err error) {
+	code := models.ErrorStatus(err, http.StatusBadRequest)
+	data, marshalErr := json.Marshal(models.HTTPError{Message: err.Error(), Code: code})
+	if marshalErr != nil {
+		log.Println(marshalErr)
+		return
+	}
+	if err := ms.publishEphemeral([]string{userID}, models.Event{Type: models.EventError, Payload: data}); err != nil {
+		log.Println(err)
 	}
 }
 
@@ -82,12 +188,14 @@ func (ms *managerService) CheckOldClient(userID string) error {
 	for {
 		existingClientFound := false
 
+		ms.RLock()
 		for client := range ms.clients {
 			if client.User.ID.Hex() == userID {
 				existingClientFound = true
 				break
 			}
 		}
+		ms.RUnlock()
 
 		if !existingClientFound {
 			break
@@ -105,11 +213,24 @@ func (ms *managerService) CheckOldClient(userID string) error {
 	return nil
 }
 
-// addClient will add clients to our clientList
-func (ms *managerService) AddClient(conn *websocket.Conn, c *gin.Context, userID string) {
+// IssueTicket creates a one-time ticket the user redeems when opening the websocket
+func (ms *managerService) IssueTicket(userID string) (string, error) {
+	return ms.ticketRepo.Issue(userID)
+}
+
+// RedeemTicket returns the user the ticket was issued to, a ticket can only be redeemed once
+func (ms *managerService) RedeemTicket(ticket string) (string, error) {
+	return ms.ticketRepo.Redeem(ticket)
+}
+
+// addClient will add clients to our clientList. A client reconnecting with the last sequence
+// it has seen is sent the events it missed before any live event.
+func (ms *managerService) AddClient(conn *websocket.Conn, c *gin.Context, userID string, lastSeq *uint64) {
 	user, err := ms.userRepo.GetUserByID(userID)
 	if err != nil {
 		log.Println(err)
+		conn.Close()
+		return
 	}
 	log.Println("New connection with userID : " + user.ID.Hex())
 
@@ -119,17 +240,30 @@ func (ms *managerService) AddClient(conn *websocket.Conn, c *gin.Context, userID
 	go clientService.ReadMessages()
 	go clientService.WriteMessages()
 
+	client := clientService.GetClient()
+	if lastSeq != nil {
+		ms.resumingLock.Lock()
+		ms.resuming[client] = []models.Event{}
+		ms.resumingLock.Unlock()
+	}
+
 	// Lock so we can manipulate
 	ms.Lock()
-	defer ms.Unlock()
-
 	// Add Client
-	ms.clients[clientService.GetClient()] = true
+	ms.clients[client] = true
+	ms.Unlock()
+
+	if lastSeq != nil {
+		ms.resume(client, *lastSeq)
+	}
 
 	ms.SendUserStatusHandler(user.ID.Hex(), models.EventNewUser)
 }
 
 func (ms *managerService) GetClients() []models.User {
+	ms.RLock()
+	defer ms.RUnlock()
+
 	var users []models.User
 	for client := range ms.clients {
 		users = append(users, client.User)
@@ -144,10 +278,10 @@ func (ms *managerService) GetClients() []models.User {
 // removeClient will remove the client and clean up
 func (ms *managerService) RemoveClient(client *models.Client) {
 	ms.Lock()
-	defer ms.Unlock()
 
 	// Check if Client exists, then delete it
-	if _, ok := ms.clients[client]; ok {
+	_, ok := ms.clients[client]
+	if ok {
 		// Store data into database
 		// ms.chatRepo.UploadChat(client.User)
 		// close connection
@@ -158,11 +292,111 @@ func (ms *managerService) RemoveClient(client *models.Client) {
 		// remove
 		delete(ms.clients, client)
 		log.Println("delete client for :", client.User.ID.Hex())
+	}
+	ms.Unlock()
+
+	ms.resumingLock.Lock()
+	delete(ms.resuming, client)
+	ms.resumingLock.Unlock()
 
+	if ok {
+		ms.stopUserTyping(client.User.ID.Hex())
 		ms.SendUserStatusHandler(client.User.ID.Hex(), models.EventLeaveUser)
 	}
 }
 
+// publish sends the event on the broadcast bus to the recipients, nobody gets it when there are none.
+// Events are numbered and buffered per user so they can be replayed.
+func (ms *managerService) publish(recipients []string, event models.Event) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	message := models.BroadcastMessage{
+		Recipients: recipients,
+		Event:      event,
+	}
+	sequences, err := ms.replayRepo.Append(recipients, event)
+	if err != nil {
+		log.Printf("failed to buffer event: %v", err)
+	} else {
+		message.Sequences = sequences
+	}
+	if err := ms.broadcastRepo.Publish(message); err != nil {
+		return fmt.Errorf("failed to publish broadcast message: %v", err)
+	}
+	return nil
+}
+
+// publishEphemeral sends the event on the broadcast bus without numbering it,
+// it is never replayed to a resuming client
+func (ms *managerService) publishEphemeral(recipients []string, event models.Event) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	message := models.BroadcastMessage{
+		Recipients: recipients,
+		Event:      event,
+	}
+	if err := ms.broadcastRepo.Publish(message); err != nil {
+		return fmt.Errorf("failed to publish broadcast message: %v", err)
+	}
+	return nil
+}
+
+// broadcast sends the event on the broadcast bus to every connected client. No instance knows
+// every connected user, so the event is not numbered and never replayed, like an ephemeral event.
+func (ms *managerService) broadcast(event models.Event) error {
+	message := models.BroadcastMessage{
+		Broadcast: true,
+		Event:     event,
+	}
+	if err := ms.broadcastRepo.Publish(message); err != nil {
+		return fmt.Errorf("failed to publish broadcast message: %v", err)
+	}
+	return nil
+}
+
+// deliverBroadcast hands a message from the bus to the recipients connected to this instance
+func (ms *managerService) deliverBroadcast(message models.BroadcastMessage) {
+	ms.RLock()
+	clients := []*models.Client{}
+	for client := range ms.clients {
+		if message.Broadcast || slices.Contains(message.Recipients, client.User.ID.Hex()) {
+			clients = append(clients, client)
+		}
+	}
+	ms.RUnlock()
+
+	for _, client := range clients {
+		event := message.Event
+		event.Seq = message.Sequences[client.User.ID.Hex()]
+		ms.deliver(client, event)
+	}
+}
+
+// deliver sends a live event to the client, or holds it while the client is resuming
+func (ms *managerService) deliver(client *models.Client, event models.Event) {
+	ms.resumingLock.Lock()
+	defer ms.resumingLock.Unlock()
+
+	if pending, ok := ms.resuming[client]; ok {
+		ms.resuming[client] = append(pending, event)
+		return
+	}
+	ms.sendToClient(client, event)
+}
+
+// sendToClient queues the event on the client without blocking, a dropped
+// numbered event can still be recovered by the client through a resume
+func (ms *managerService) sendToClient(client *models.Client, event models.Event) {
+	select {
+	case client.Egress <- event:
+	default:
+		// Never let one slow client stall the bus for everyone else
+		log.Println("egress full, dropping event for :", client.User.ID.Hex())
+	}
+}
+
 // Event
 func (ms *managerService) SendMessageHandler(event models.Event, c *models.Client) error {
 	// Marshal Payload into wanted format
@@ -170,7 +404,42 @@ func (ms *managerService) SendMessageHandler(event models.Event, c *models.Clien
 	if err := json.Unmarshal(event.Payload, &chatevent); err != nil {
 		return fmt.Errorf("bad payload in request: %v", err)
 	}
+
+	// The sender is always the authenticated client, never the payload, and the server picks the ID
+	chatevent.From = c.User.ID.Hex()
+	chatevent.ID = ""
+	return ms.SendUserMessage(chatevent)
+}
+
+// SendUserMessage sends a message of a user who is not necessarily connected,
+// like a scheduled message coming due, the user must still be a member of the chat.
+// A message sent with an ID that is already stored is not sent again.
+func (ms *managerService) SendUserMessage(chatevent models.SendMessageEvent) error {
+	if utf8.RuneCountInString(chatevent.Message) > models.MaxMessageRunes {
+		return models.ErrMessageTooLong
+	}
+	if err := ms.chatService.CheckMember(chatevent.ChatID, chatevent.From); err != nil {
+		return err
+	}
+	return ms.sendMessage(&chatevent)
+}
+
+// SendBotMessage posts a reply of Meeyok AI into the chat
+func (ms *managerService) SendBotMessage(chatID string, message string) error {
+	return ms.sendMessage(&models.SendMessageEvent{
+		ChatID:  chatID,
+		Message: message,
+		From:    models.MeeyokAI,
+	})
+}
+
+// sendMessage stores the message and fans it out to the members of the chat,
+// the event is filled in with what the server adds like the message ID
+func (ms *managerService) sendMessage(chatevent *models.SendMessageEvent) error {
 	message := chatevent.Message
+	if strings.TrimSpace(message) == "" && len(chatevent.Attachments) == 0 {
+		return models.ErrEmptyMessage
+	}
 
 	chatevent.CreatedAt = time.Now()
 
@@ -181,23 +450,58 @@ func (ms *managerService) SendMessageHandler(event models.Event, c *models.Clien
 	}
 
 	// Store the message
-	newMessage := ms.chatRepo.NewMessage(message, chatevent.From)
-
-	// manage new message
-	chat.Messages = append(chat.Messages, newMessage)
-	err = ms.chatRepo.AppendMessage(chat.ID.Hex(), newMessage)
-	if err != nil {
+	newMessage := ms.messageRepo.NewMessage(chat.ID, message, chatevent.From)
+	if chatevent.ID != "" {
+		// A sender retrying the message, like the scheduler, picked the ID up front so it is stored once
+		if _, err := ms.messageRepo.GetMessage(chatevent.ChatID, chatevent.ID); err == nil {
+			return nil
+		}
+		newMessage.ID, err = primitive.ObjectIDFromHex(chatevent.ID)
+		if err != nil {
+			return models.ErrMessageNotFound
+		}
+	}
+	if chatevent.ReplyTo != "" {
+		if err := ms.attachReply(&newMessage, chatevent.ReplyTo); err != nil {
+			return err
+		}
+		chatevent.ThreadID = newMessage.ThreadID.Hex()
+	}
+	if len(chatevent.Attachments) > 0 {
+		newMessage.Attachments, err = ms.attachmentService.ClaimAttachments(newMessage, chatevent.Attachments)
+		if err != nil {
+			return err
+		}
+		chatevent.Attachments = newMessage.Attachments
+	}
+	newMessage.Mentions = ms.chatService.ParseMentions(chat.Users, message)
+	chatevent.Mentions = newMessage.Mentions
+	newMessage.ExpiresAt = chat.MessageExpiry(newMessage.CreatedAt)
+	chatevent.ExpiresAt = newMessage.ExpiresAt
+	err = ms.messageRepo.InsertMessage(newMessage)
+	if errors.Is(err, models.ErrMessageExists) {
+		// Another attempt stored it in the meantime
+		return nil
+	} else if err != nil {
+		// The claimed attachments would otherwise point at a message that does not exist
+		if releaseErr := ms.attachmentService.ReleaseAttachments(newMessage); releaseErr != nil {
+			log.Printf("failed to release the attachments of message %s: %v", newMessage.ID.Hex(), releaseErr)
+		}
 		return fmt.Errorf("failed to append new message to database: %v", err)
 	}
 
+	chatevent.ID = newMessage.ID.Hex()
 	data, err := json.Marshal(chatevent)
 	if err != nil {
 		return fmt.Errorf("failed to marshal broadcast message: %v", err)
 	}
 
+	// A sent message ends the typing indicator of the sender
+	ms.stopTyping(typingKey{chatID: chatevent.ChatID, userID: chatevent.From})
+
 	// Send message to Meeyok AI
-	if message == "@Meeyok AI" {
-		ms.sendEventToQueue(chatevent.ChatID)
+	if prompt, ok := aiPrompt(chat, chatevent.From, message); ok {
+		ms.sendEventToQueue(chat, newMessage, prompt)
 	}
 
 	// Place payload into an Event
@@ -205,24 +509,198 @@ func (ms *managerService) SendMessageHandler(event models.Event, c *models.Clien
 	outgoingEvent.Payload = data
 	outgoingEvent.Type = models.EventNewMessage
 
-	for client := range ms.clients {
-		for _, userID := range chat.Users {
-			if client.User.ID.Hex() == userID {
-				client.Egress <- outgoingEvent
-			}
+	if err := ms.publish(chat.Users, outgoingEvent); err != nil {
+		return err
+	}
+	if err := ms.sendMentions(*chatevent); err != nil {
+		return err
+	}
+
+	// Messages of the server do not make a chat unread
+	if chatevent.From == models.SystemSender {
+		return nil
+	}
+	for userID, count := range ms.chatService.CountNewMessage(newMessage, chat.Users) {
+		lastRead := ""
+		if userID == chatevent.From {
+			lastRead = chatevent.ID
 		}
+		if err := ms.sendUnreadCount(userID, chatevent.ChatID, count, lastRead); err != nil {
+			log.Println(err)
+		}
+	}
+	return nil
+}
+
+// sendUnreadCount pushes the new unread counter of a chat to every socket of the user
+func (ms *managerService) sendUnreadCount(userID string, chatID string, count int, lastRead string) error {
+	data, err := json.Marshal(models.UnreadCountEvent{
+		ChatID:            chatID,
+		UnreadCount:       count,
+		LastReadMessageID: lastRead,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal unread count: %v", err)
+	}
+	return ms.publish([]string{userID}, models.Event{Type: models.EventUnreadCount, Payload: data})
+}
+
+// sendMentions notifies the users mentioned in a new message, except its sender
+func (ms *managerService) sendMentions(chatevent models.SendMessageEvent) error {
+	recipients := slices.DeleteFunc(chat.MentionedUsers(chatevent.Mentions), func(userID string) bool {
+		return userID == chatevent.From
+	})
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(models.MentionEvent{
+		ChatID:    chatevent.ChatID,
+		MessageID: chatevent.ID,
+		From:      chatevent.From,
+		Message:   chatevent.Message,
+		CreatedAt: chatevent.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal mention: %v", err)
+	}
+	return ms.publish(recipients, models.Event{Type: models.EventMention, Payload: data})
+}
+
+// attachReply makes the message a reply to another message of the same chat,
+// replies to a reply join the thread of the message they reply to
+func (ms *managerService) attachReply(message *models.Message, replyTo string) error {
+	parent, err := ms.messageRepo.GetMessage(message.ChatID.Hex(), replyTo)
+	if err != nil {
+		return err
+	}
+	if parent.Deleted {
+		return models.ErrMessageNotFound
+	}
+
+	threadID := parent.ID
+	if parent.ThreadID != nil {
+		threadID = *parent.ThreadID
 	}
+	message.ReplyTo = &parent.ID
+	message.ThreadID = &threadID
 	return nil
 }
 
-func (ms *managerService) sendEventToQueue(chatID string) {
-	// system event for ai service test
-	queuePublisherPayload := models.QueuePublisherPayload{
-		From: chatID,
+func (ms *managerService) MarkDeliveredHandler(event models.Event, c *models.Client) error {
+	return ms.markMessages(event, c, models.ReceiptDelivered)
+}
+
+func (ms *managerService) MarkReadHandler(event models.Event, c *models.Client) error {
+	return ms.markMessages(event, c, models.ReceiptRead)
+}
+
+// markMessages stores the receipts of the client and sends them back to the senders of the messages
+func (ms *managerService) markMessages(event models.Event, c *models.Client, status string) error {
+	var markEvent models.MarkMessagesEvent
+	if err := json.Unmarshal(event.Payload, &markEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+
+	userID := c.User.ID.Hex()
+	if err := ms.chatService.CheckMember(markEvent.ChatID, userID); err != nil {
+		return err
+	}
+	messages, err := ms.chatService.MarkMessages(markEvent.ChatID, userID, markEvent.MessageID, status)
+	if err != nil {
+		return fmt.Errorf("failed to mark messages as %s: %v", status, err)
+	}
+
+	// Reading moves the read marker, the other sockets of the user update their badge too
+	if status == models.ReceiptRead {
+		count, lastRead, err := ms.chatService.MarkRead(markEvent.ChatID, userID, markEvent.MessageID)
+		if err != nil {
+			return fmt.Errorf("failed to move read marker: %v", err)
+		}
+		if err := ms.sendUnreadCount(userID, markEvent.ChatID, count, lastRead); err != nil {
+			return err
+		}
+	}
+
+	// Group the messages by sender so each sender only hears about their own messages
+	messageIDsBySender := make(map[string][]string)
+	for _, message := range messages {
+		messageIDsBySender[message.From] = append(messageIDsBySender[message.From], message.ID.Hex())
+	}
+
+	now := time.Now()
+	for sender, messageIDs := range messageIDsBySender {
+		data, err := json.Marshal(models.ReceiptEvent{
+			ChatID:     markEvent.ChatID,
+			UserID:     userID,
+			Status:     status,
+			MessageIDs: messageIDs,
+			At:         now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal receipt: %v", err)
+		}
+		if err := ms.publish([]string{sender}, models.Event{Type: models.EventReceipt, Payload: data}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// resume sends the client the events it missed since the last sequence it has seen, then the
+// live events that arrived meanwhile. Events are sent once, in order of their sequence.
+func (ms *managerService) resume(c *models.Client, lastSeq uint64) {
+	events, currentSeq, complete, err := ms.replayRepo.Since(c.User.ID.Hex(), lastSeq)
+	if err != nil {
+		log.Printf("failed to read replay buffer: %v", err)
+		events, currentSeq, complete = nil, lastSeq, false
+	}
+
+	sent := lastSeq
+	for _, missed := range events {
+		ms.sendToClient(c, missed)
+		sent = max(sent, missed.Seq)
+	}
+	data, err := json.Marshal(models.ResumedEvent{
+		LastSeq:  max(currentSeq, sent),
+		Complete: complete,
+	})
+	if err != nil {
+		log.Printf("failed to marshal resumed event: %v", err)
+	} else {
+		ms.sendToClient(c, models.Event{Type: models.EventResumed, Payload: data})
+	}
+
+	ms.resumingLock.Lock()
+	defer ms.resumingLock.Unlock()
+	for _, live := range ms.resuming[c] {
+		// Numbered events already replayed are not sent twice
+		if live.Seq != 0 && live.Seq <= sent {
+			continue
+		}
+		ms.sendToClient(c, live)
+	}
+	delete(ms.resuming, c)
+}
+
+// sendEventToQueue asks the AI worker to reply to the trigger message, with the context of the chat
+func (ms *managerService) sendEventToQueue(chat models.Chat, trigger models.Message, prompt string) {
+	if err := ms.checkAIRateLimit(trigger.From); err != nil {
+		log.Printf("skipped the AI request of message %s: %v", trigger.ID.Hex(), err)
+		ms.sendUserError(trigger.From, err)
+		return
+	}
+	queuePublisherPayload, err := ms.chatService.AssembleContext(chat, trigger)
+	if err != nil {
+		log.Printf("failed to assemble the AI context of chat %s: %v", chat.ID.Hex(), err)
+		return
+	}
+	queuePublisherPayload.CorrelationID = primitive.NewObjectID().Hex()
+	queuePublisherPayload.Prompt = prompt
 	payload, err := json.Marshal(queuePublisherPayload)
 	if err != nil {
-		log.Fatalf("error marshalling sendMessageEvent: %v", err)
+		log.Printf("error marshalling sendMessageEvent: %v", err)
+		return
 	}
 
 	var event models.Event
@@ -231,10 +709,35 @@ func (ms *managerService) sendEventToQueue(chatID string) {
 
 	outgoingEvent, err := json.Marshal(event)
 	if err != nil {
-		log.Fatalf("error marshalling sendMessageEvent: %v", err)
+		log.Printf("error marshalling sendMessageEvent: %v", err)
+		return
 	}
 
-	ms.queuePublisher.SQSSendMessage(outgoingEvent)
+	// The request is tracked before it is published, so a reply never arrives for an unknown request
+	now := time.Now()
+	err = ms.aiRequestRepo.InsertAIRequest(models.AIRequest{
+		ID:               queuePublisherPayload.CorrelationID,
+		ChatID:           chat.ID,
+		TriggerMessageID: trigger.ID,
+		RequestedBy:      trigger.From,
+		Status:           models.AIRequestPending,
+		Attempts:         1,
+		SentAt:           now,
+		Payload:          string(outgoingEvent),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		// An untracked request would never time out or fail visibly, so it is not published
+		log.Printf("failed to record AI request %s: %v", queuePublisherPayload.CorrelationID, err)
+		ms.sendUserError(trigger.From, models.ErrAIUnavailable)
+		return
+	}
+
+	// A request that could not be published is published again once it times out
+	if err := ms.queuePublisher.Publish(outgoingEvent); err != nil {
+		log.Printf("failed to send event to queue: %v", err)
+	}
 }
 
 func (ms *managerService) SendUserStatusHandler(userId string, eventType string) error {
@@ -251,13 +754,15 @@ func (ms *managerService) SendUserStatusHandler(userId string, eventType string)
 	outgoingEvent.Payload = data
 	outgoingEvent.Type = eventType
 
-	for client := range ms.clients {
-		client.Egress <- outgoingEvent
-	}
-	return nil
+	return ms.broadcast(outgoingEvent)
 }
 
+// SendNewGroupHandler tells the members of a new group chat about it
 func (ms *managerService) SendNewGroupHandler(chatID string) error {
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
 	payload := models.NewGroupEvent{
 		ChatID: chatID,
 	}
@@ -271,8 +776,41 @@ func (ms *managerService) SendNewGroupHandler(chatID string) error {
 	outgoingEvent.Payload = data
 	outgoingEvent.Type = models.EventNewGroup
 
-	for client := range ms.clients {
-		client.Egress <- outgoingEvent
+	return ms.publish(users, outgoingEvent)
+}
+
+// SendPinnedChangedHandler tells the members of the chat that the user pinned or unpinned a message
+func (ms *managerService) SendPinnedChangedHandler(chatID string, messageID string, userID string, pinned bool) error {
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
 	}
-	return nil
+	data, err := json.Marshal(models.PinnedChangedEvent{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Pinned:    pinned,
+		By:        userID,
+		At:        time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(users, models.Event{Type: models.EventPinnedChanged, Payload: data})
+}
+
+// SendRoleChangedHandler tells the members of a group chat about the new role of a member
+func (ms *managerService) SendRoleChangedHandler(chatID string, userID string, role string) error {
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+	data, err := json.Marshal(models.RoleChangedEvent{
+		ChatID: chatID,
+		UserID: userID,
+		Role:   role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(users, models.Event{Type: models.EventRoleChanged, Payload: data})
 }
@@ -0,0 +1,84 @@
+package Websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// SendMemberRemovedHandler tells the members of the chat and the removed user about the removal,
+// and writes it into the history of the chat as a system message
+func (ms *managerService) SendMemberRemovedHandler(removal models.MemberRemoval) error {
+	ms.stopTyping(typingKey{chatID: removal.ChatID, userID: removal.UserID})
+
+	data, err := json.Marshal(models.MemberRemovedEvent{
+		ChatID:    removal.ChatID,
+		UserID:    removal.UserID,
+		RemovedBy: removal.RemovedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	recipients := append([]string{removal.UserID}, removal.Members...)
+	if err := ms.publish(recipients, models.Event{Type: models.EventMemberRemoved, Payload: data}); err != nil {
+		return err
+	}
+
+	// Nobody is left to read the history of a deleted chat
+	if len(removal.Members) == 0 {
+		return nil
+	}
+
+	text := ms.displayName(removal.UserID) + " left"
+	if removal.RemovedBy != "" {
+		text = ms.displayName(removal.RemovedBy) + " removed " + ms.displayName(removal.UserID)
+	}
+	if err := ms.SendSystemMessage(removal.ChatID, text); err != nil {
+		return err
+	}
+
+	if removal.NewOwner != "" {
+		if err := ms.SendRoleChangedHandler(removal.ChatID, removal.NewOwner, models.RoleOwner); err != nil {
+			return err
+		}
+		return ms.SendSystemMessage(removal.ChatID, ms.displayName(removal.NewOwner)+" is now the owner")
+	}
+	return nil
+}
+
+// SendChatDeletedHandler tells the members of a deleted chat it is gone
+func (ms *managerService) SendChatDeletedHandler(chatID string, members []string, userID string) error {
+	for _, member := range members {
+		ms.stopTyping(typingKey{chatID: chatID, userID: member})
+	}
+
+	data, err := json.Marshal(models.ChatDeletedEvent{
+		ChatID:    chatID,
+		DeletedBy: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(members, models.Event{Type: models.EventChatDeleted, Payload: data})
+}
+
+// SendSystemMessage writes a message of the server into the chat
+func (ms *managerService) SendSystemMessage(chatID string, message string) error {
+	return ms.sendMessage(&models.SendMessageEvent{
+		ChatID:  chatID,
+		Message: message,
+		From:    models.SystemSender,
+	})
+}
+
+// displayName is the username of the user, or the ID when it cannot be found
+func (ms *managerService) displayName(userID string) string {
+	user, err := ms.userRepo.GetUserByID(userID)
+	if err != nil || user.Username == "" {
+		log.Printf("failed to get username of %s: %v", userID, err)
+		return userID
+	}
+	return user.Username
+}
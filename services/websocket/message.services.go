@@ -0,0 +1,156 @@
+package Websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// EditMessageHandler changes the text of a message the client sent
+func (ms *managerService) EditMessageHandler(event models.Event, c *models.Client) error {
+	var editEvent models.EditMessageEvent
+	if err := json.Unmarshal(event.Payload, &editEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+
+	userID := c.User.ID.Hex()
+	if err := ms.chatService.CheckMember(editEvent.ChatID, userID); err != nil {
+		return err
+	}
+	message, err := ms.chatService.EditMessage(editEvent.ChatID, editEvent.MessageID, userID, editEvent.Message)
+	if err != nil {
+		return err
+	}
+	return ms.SendMessageEditedHandler(message)
+}
+
+// DeleteMessageHandler deletes a message for the client, or for everyone
+func (ms *managerService) DeleteMessageHandler(event models.Event, c *models.Client) error {
+	var deleteEvent models.DeleteMessageEvent
+	if err := json.Unmarshal(event.Payload, &deleteEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+
+	userID := c.User.ID.Hex()
+	if err := ms.chatService.CheckMember(deleteEvent.ChatID, userID); err != nil {
+		return err
+	}
+	message, err := ms.chatService.DeleteMessage(deleteEvent.ChatID, deleteEvent.MessageID, userID, deleteEvent.ForEveryone)
+	if err != nil {
+		return err
+	}
+	return ms.SendMessageDeletedHandler(message, userID, deleteEvent.ForEveryone)
+}
+
+// SendMessageEditedHandler tells the members of the chat about the new text of the message
+func (ms *managerService) SendMessageEditedHandler(message models.Message) error {
+	users, err := ms.chatRepo.GetChatUsers(message.ChatID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	editedAt := time.Now()
+	if message.EditedAt != nil {
+		editedAt = *message.EditedAt
+	}
+	data, err := json.Marshal(models.MessageEditedEvent{
+		ChatID:    message.ChatID.Hex(),
+		MessageID: message.ID.Hex(),
+		Message:   message.Message,
+		Mentions:  message.Mentions,
+		EditedAt:  editedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(users, models.Event{Type: models.EventMessageEdited, Payload: data})
+}
+
+// SendMessageDeletedHandler tells the members of the chat to drop the message,
+// a message deleted only for the user only goes to the other sockets of that user
+func (ms *managerService) SendMessageDeletedHandler(message models.Message, userID string, forEveryone bool) error {
+	recipients := []string{userID}
+	if forEveryone {
+		users, err := ms.chatRepo.GetChatUsers(message.ChatID.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to get chat: %v", err)
+		}
+		recipients = users
+	}
+
+	deletedAt := time.Now()
+	if message.DeletedAt != nil {
+		deletedAt = *message.DeletedAt
+	}
+	data, err := json.Marshal(models.MessageDeletedEvent{
+		ChatID:      message.ChatID.Hex(),
+		MessageID:   message.ID.Hex(),
+		ForEveryone: forEveryone,
+		DeletedAt:   deletedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(recipients, models.Event{Type: models.EventMessageDeleted, Payload: data})
+}
+
+// AddReactionHandler reacts to a message with an emoji
+func (ms *managerService) AddReactionHandler(event models.Event, c *models.Client) error {
+	return ms.changeReaction(event, c, true)
+}
+
+// RemoveReactionHandler takes a reaction of the client back
+func (ms *managerService) RemoveReactionHandler(event models.Event, c *models.Client) error {
+	return ms.changeReaction(event, c, false)
+}
+
+func (ms *managerService) changeReaction(event models.Event, c *models.Client, added bool) error {
+	var reactionEvent models.ReactionEvent
+	if err := json.Unmarshal(event.Payload, &reactionEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+
+	userID := c.User.ID.Hex()
+	if err := ms.chatService.CheckMember(reactionEvent.ChatID, userID); err != nil {
+		return err
+	}
+
+	var message models.Message
+	var err error
+	if added {
+		message, err = ms.chatService.AddReaction(reactionEvent.ChatID, reactionEvent.MessageID, userID, reactionEvent.Emoji)
+	} else {
+		message, err = ms.chatService.RemoveReaction(reactionEvent.ChatID, reactionEvent.MessageID, userID, reactionEvent.Emoji)
+	}
+	if err != nil {
+		return err
+	}
+	return ms.SendReactionChangedHandler(message, userID, reactionEvent.Emoji, added)
+}
+
+// SendReactionChangedHandler tells the members of the chat about the reactions of the message
+func (ms *managerService) SendReactionChangedHandler(message models.Message, userID string, emoji string, added bool) error {
+	users, err := ms.chatRepo.GetChatUsers(message.ChatID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	reactions := message.Reactions
+	if reactions == nil {
+		reactions = map[string][]string{}
+	}
+	data, err := json.Marshal(models.ReactionChangedEvent{
+		ChatID:    message.ChatID.Hex(),
+		MessageID: message.ID.Hex(),
+		UserID:    userID,
+		Emoji:     emoji,
+		Added:     added,
+		Reactions: reactions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(users, models.Event{Type: models.EventReactionChanged, Payload: data})
+}
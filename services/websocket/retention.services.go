@@ -0,0 +1,61 @@
+package Websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// SendRetentionChangedHandler tells the members of the chat about its new message lifetime,
+// and writes the change into the history of the chat as a system message
+func (ms *managerService) SendRetentionChangedHandler(chatID string, userID string, ttl time.Duration) error {
+	users, err := ms.chatRepo.GetChatUsers(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+	data, err := json.Marshal(models.RetentionChangedEvent{
+		ChatID:     chatID,
+		MessageTTL: int64(ttl / time.Second),
+		By:         userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	if err := ms.publish(users, models.Event{Type: models.EventRetentionChanged, Payload: data}); err != nil {
+		return err
+	}
+
+	text := ms.displayName(userID) + " turned off disappearing messages"
+	if ttl > 0 {
+		text = ms.displayName(userID) + " turned on disappearing messages, new messages disappear after " + ttlLabel(ttl)
+	}
+	return ms.SendSystemMessage(chatID, text)
+}
+
+// SendMessagesExpiredHandler tells the members of the chat which of its messages disappeared
+func (ms *managerService) SendMessagesExpiredHandler(expired models.ExpiredMessages) error {
+	if len(expired.Members) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(models.MessagesExpiredEvent{
+		ChatID:     expired.ChatID,
+		MessageIDs: expired.MessageIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast message: %v", err)
+	}
+	return ms.publish(expired.Members, models.Event{Type: models.EventMessagesExpired, Payload: data})
+}
+
+// ttlLabel writes a message lifetime the way people say it, like "24 hours" or "7 days"
+func ttlLabel(ttl time.Duration) string {
+	if ttl%(24*time.Hour) == 0 && ttl > 24*time.Hour {
+		return fmt.Sprintf("%d days", ttl/(24*time.Hour))
+	}
+	if ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", ttl/time.Hour)
+	}
+	return ttl.String()
+}
@@ -0,0 +1,121 @@
+package Websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Meeyok-Chat/backend/models"
+)
+
+// typingTimeout is how long a typing_start lasts without being renewed.
+// Clients keep sending typing_start while the user types, so a crashed
+// client stops showing as typing once the timer fires.
+var typingTimeout = 6 * time.Second
+
+type typingKey struct {
+	chatID string
+	userID string
+}
+
+// TypingStartHandler tells the other members of the chat that the client is typing
+func (ms *managerService) TypingStartHandler(event models.Event, c *models.Client) error {
+	var typingEvent models.TypingEvent
+	if err := json.Unmarshal(event.Payload, &typingEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+	key := typingKey{chatID: typingEvent.ChatID, userID: c.User.ID.Hex()}
+	if err := ms.chatService.CheckMember(key.chatID, key.userID); err != nil {
+		return err
+	}
+
+	ms.typingLock.Lock()
+	timer, renewed := ms.typing[key]
+	if renewed {
+		timer.Reset(typingTimeout)
+	} else {
+		ms.typing[key] = time.AfterFunc(typingTimeout, func() {
+			ms.stopTyping(key)
+		})
+	}
+	ms.typingLock.Unlock()
+
+	// Renewals only extend the timer, the others already know
+	if renewed {
+		return nil
+	}
+	if err := ms.sendTyping(key, models.EventTypingStart); err != nil {
+		ms.stopTyping(key)
+		return err
+	}
+	return nil
+}
+
+// TypingStopHandler tells the other members of the chat that the client stopped typing
+func (ms *managerService) TypingStopHandler(event models.Event, c *models.Client) error {
+	var typingEvent models.TypingEvent
+	if err := json.Unmarshal(event.Payload, &typingEvent); err != nil {
+		return fmt.Errorf("bad payload in request: %v", err)
+	}
+	ms.stopTyping(typingKey{chatID: typingEvent.ChatID, userID: c.User.ID.Hex()})
+	return nil
+}
+
+// stopTyping clears the typing state and fans out a typing_stop if the user was typing
+func (ms *managerService) stopTyping(key typingKey) {
+	ms.typingLock.Lock()
+	timer, ok := ms.typing[key]
+	if ok {
+		timer.Stop()
+		delete(ms.typing, key)
+	}
+	ms.typingLock.Unlock()
+
+	if !ok {
+		return
+	}
+	if err := ms.sendTyping(key, models.EventTypingStop); err != nil {
+		log.Println(err)
+	}
+}
+
+// stopUserTyping clears every chat the user is typing in, used when the client leaves
+func (ms *managerService) stopUserTyping(userID string) {
+	ms.typingLock.Lock()
+	keys := []typingKey{}
+	for key := range ms.typing {
+		if key.userID == userID {
+			keys = append(keys, key)
+		}
+	}
+	ms.typingLock.Unlock()
+
+	for _, key := range keys {
+		ms.stopTyping(key)
+	}
+}
+
+// sendTyping fans a typing event out to the other members of the chat, without persisting it
+func (ms *managerService) sendTyping(key typingKey, eventType string) error {
+	users, err := ms.chatRepo.GetChatUsers(key.chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %v", err)
+	}
+
+	recipients := []string{}
+	for _, userID := range users {
+		if userID != key.userID {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(models.TypingEvent{ChatID: key.chatID, UserID: key.userID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal typing event: %v", err)
+	}
+	return ms.publishEphemeral(recipients, models.Event{Type: eventType, Payload: data})
+}